@@ -0,0 +1,68 @@
+package adaptive
+
+import (
+	"math"
+	"time"
+)
+
+// Algorithm computes the next limit from the outcome of a request. It is
+// called with the limiter locked.
+type Algorithm interface {
+	Update(limit float64, rtt time.Duration, inFlight int, dropped bool) float64
+}
+
+type aimd struct {
+	increase float64
+	backoff  float64
+}
+
+// AIMD adds increase to the limit after each successful request while the
+// limit is in use, and multiplies it by backoff after each dropped one.
+func AIMD(increase float64, backoff float64) Algorithm {
+	return &aimd{increase: increase, backoff: backoff}
+}
+
+func (a *aimd) Update(limit float64, rtt time.Duration, inFlight int, dropped bool) float64 {
+	if dropped {
+		return limit * a.backoff
+	}
+	if float64(inFlight)*2 >= limit {
+		return limit + a.increase
+	}
+	return limit
+}
+
+type vegas struct {
+	alpha  float64
+	beta   float64
+	minRTT time.Duration
+}
+
+// Vegas estimates the requests queued by the dependency from the increase of
+// the latency over the lowest one observed. The limit grows while fewer than
+// alpha requests are queued and shrinks when more than beta are, or when
+// requests are dropped.
+func Vegas(alpha float64, beta float64) Algorithm {
+	return &vegas{alpha: alpha, beta: beta}
+}
+
+func (v *vegas) Update(limit float64, rtt time.Duration, inFlight int, dropped bool) float64 {
+	step := math.Max(1, math.Log10(limit))
+	if dropped {
+		return limit - step
+	}
+	if rtt <= 0 {
+		return limit
+	}
+	if v.minRTT == 0 || rtt < v.minRTT {
+		v.minRTT = rtt
+	}
+	queue := limit * (1 - float64(v.minRTT)/float64(rtt))
+	switch {
+	case queue < v.alpha:
+		return limit + step
+	case queue > v.beta:
+		return limit - step
+	}
+	return limit
+}
@@ -0,0 +1,203 @@
+package adaptive
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+	"github.com/JuanAller/request-builder/src/api/caller"
+)
+
+type Config struct {
+	// Algorithm is AIMD(1, 0.9) by default.
+	Algorithm    Algorithm
+	InitialLimit int
+	// MinLimit and MaxLimit bound the limit, 1 and 1000 by default.
+	MinLimit int
+	MaxLimit int
+	// IsDrop defaults to DefaultIsDrop.
+	IsDrop func(statusCode int, err error) bool
+	Now    func() time.Time
+}
+
+// DefaultIsDrop counts transport errors, timeouts, 429 and 5xx. Requests
+// cancelled by the caller, such as the losing hedged request, are not drops.
+func DefaultIsDrop(statusCode int, err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	return err != nil || statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// Limiter bounds the in-flight requests to a dependency with a limit that
+// adapts to their latency and errors.
+type Limiter struct {
+	name     string
+	config   Config
+	mutex    sync.Mutex
+	limit    float64
+	inFlight int
+	changed  chan struct{}
+}
+
+func New(name string, config Config) *Limiter {
+	if config.Algorithm == nil {
+		config.Algorithm = AIMD(1, 0.9)
+	}
+	if config.MinLimit <= 0 {
+		config.MinLimit = 1
+	}
+	if config.MaxLimit <= 0 {
+		config.MaxLimit = 1000
+	}
+	if config.InitialLimit <= 0 {
+		config.InitialLimit = 10
+	}
+	if config.IsDrop == nil {
+		config.IsDrop = DefaultIsDrop
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return &Limiter{
+		name:    name,
+		config:  config,
+		limit:   float64(config.InitialLimit),
+		changed: make(chan struct{}),
+	}
+}
+
+func (l *Limiter) Name() string {
+	return l.name
+}
+
+func (l *Limiter) Limit() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return int(l.limit)
+}
+
+func (l *Limiter) InFlight() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.inFlight
+}
+
+// Enter waits until fewer requests than the limit are in flight. The returned
+// done must be called with the outcome of the request.
+func (l *Limiter) Enter(ctx context.Context) (func(statusCode int, err error), error) {
+	start, err := l.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(statusCode int, err error) {
+		l.done(l.config.Now().Sub(start), l.config.IsDrop(statusCode, err))
+	}, nil
+}
+
+func (l *Limiter) enter(ctx context.Context) (time.Time, error) {
+	for {
+		l.mutex.Lock()
+		if l.inFlight < int(l.limit) {
+			l.inFlight++
+			l.mutex.Unlock()
+			return l.config.Now(), nil
+		}
+		changed := l.changed
+		l.mutex.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return time.Time{}, ctx.Err()
+		}
+	}
+}
+
+func (l *Limiter) done(rtt time.Duration, dropped bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	limit := l.config.Algorithm.Update(l.limit, rtt, l.inFlight, dropped)
+	if limit < float64(l.config.MinLimit) {
+		limit = float64(l.config.MinLimit)
+	}
+	if limit > float64(l.config.MaxLimit) {
+		limit = float64(l.config.MaxLimit)
+	}
+	l.limit = limit
+	l.inFlight--
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// leave frees the slot of a request whose outcome says nothing about the
+// dependency, without updating the limit.
+func (l *Limiter) leave() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.inFlight--
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// Acquire lets a Limiter bound the attempts of a restCaller. Attempts are
+// judged by their response, not by the error of the ResponseHandler, so a 404
+// is not a drop. Attempts that were not sent, because a later guard rejected
+// them, free their slot without updating the limit.
+func (l *Limiter) Acquire(ctx context.Context) (func(resp *builder.Response, err error), error) {
+	start, err := l.enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(resp *builder.Response, err error) {
+		if resp == nil {
+			l.leave()
+			return
+		}
+		l.done(l.config.Now().Sub(start), l.config.IsDrop(resp.StatusCode, resp.Error))
+	}, nil
+}
+
+// AcquireCall lets a Limiter bound the calls of caller.InParallelCallsLimited.
+// The latency of a call includes its retries and backoff, so only whether it
+// was dropped updates the limit. A caller.StatusError is judged by its status
+// code.
+func (l *Limiter) AcquireCall(ctx context.Context) (func(err error), error) {
+	if _, err := l.enter(ctx); err != nil {
+		return nil, err
+	}
+	return func(err error) {
+		statusCode := 0
+		var statusErr *caller.StatusError
+		if errors.As(err, &statusErr) {
+			statusCode, err = statusErr.StatusCode, nil
+		}
+		l.done(0, l.config.IsDrop(statusCode, err))
+	}, nil
+}
+
+// Middleware bounds the requests in flight of a builder. Hedged requests that
+// lost free their slot without updating the limit.
+func Middleware(limiter *Limiter) builder.Middleware {
+	return func(next builder.HttpClient) builder.HttpClient {
+		return builder.HttpClientFunc(func(request *http.Request) (*http.Response, error) {
+			start, err := limiter.enter(request.Context())
+			if err != nil {
+				return nil, err
+			}
+			response, err := next.Do(request)
+			if builder.HedgeLost(request) {
+				limiter.leave()
+				return response, err
+			}
+			statusCode := 0
+			if response != nil {
+				statusCode = response.StatusCode
+			}
+			limiter.done(limiter.config.Now().Sub(start), limiter.config.IsDrop(statusCode, err))
+			return response, err
+		})
+	}
+}
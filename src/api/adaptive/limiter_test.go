@@ -0,0 +1,169 @@
+package adaptive
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"net/http"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+	"github.com/JuanAller/request-builder/src/api/caller"
+	"github.com/JuanAller/request-builder/src/api/mock"
+)
+
+func TestLimiter_AIMD(t *testing.T) {
+	limiter := New("users", Config{Algorithm: AIMD(1, 0.5), InitialLimit: 4, MinLimit: 2})
+
+	dones := make([]func(statusCode int, err error), 0, 4)
+	for i := 0; i < 4; i++ {
+		done, err := limiter.Enter(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		dones = append(dones, done)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Enter(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Expected to wait for the limit, but got %v", err)
+	}
+
+	dones[0](200, nil)
+	if limit := limiter.Limit(); limit != 5 {
+		t.Errorf("Expected the limit to increase to 5, but got %v", limit)
+	}
+	dones[1](503, nil)
+	if limit := limiter.Limit(); limit != 2 {
+		t.Errorf("Expected the limit to back off to 2, but got %v", limit)
+	}
+	dones[2](0, errors.New("connection refused"))
+	if limit := limiter.Limit(); limit != 2 {
+		t.Errorf("Expected the limit to stay at the minimum, but got %v", limit)
+	}
+	dones[3](0, context.Canceled)
+	if limit := limiter.Limit(); limit != 3 {
+		t.Errorf("Expected a cancelled request not to be a drop, but got limit %v", limit)
+	}
+	if inFlight := limiter.InFlight(); inFlight != 0 {
+		t.Errorf("Expected no request in flight, but got %v", inFlight)
+	}
+}
+
+func TestVegas(t *testing.T) {
+	vegas := Vegas(3, 6)
+	limit := vegas.Update(20, 10*time.Millisecond, 20, false)
+	if limit <= 20 {
+		t.Errorf("Expected the limit to grow without queueing, but got %v", limit)
+	}
+	limit = vegas.Update(20, 20*time.Millisecond, 20, false)
+	if limit >= 20 {
+		t.Errorf("Expected the limit to shrink when latency doubles, but got %v", limit)
+	}
+	limit = vegas.Update(20, 10*time.Millisecond, 20, true)
+	if limit >= 20 {
+		t.Errorf("Expected the limit to shrink on drops, but got %v", limit)
+	}
+}
+
+type callerFunc func() error
+
+func (f callerFunc) ExecuteCall() error {
+	return f()
+}
+
+func TestLimiter_InParallelCalls(t *testing.T) {
+	limiter := New("users", Config{InitialLimit: 2, MaxLimit: 2})
+	var mutex sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	callers := make([]caller.Caller, 10)
+	for i := range callers {
+		callers[i] = callerFunc(func() error {
+			mutex.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mutex.Unlock()
+			time.Sleep(time.Millisecond)
+			mutex.Lock()
+			inFlight--
+			mutex.Unlock()
+			return nil
+		})
+	}
+	if err := caller.InParallelCallsLimited(context.Background(), limiter, callers...); err != nil {
+		t.Fatal(err)
+	}
+	if maxInFlight > 2 {
+		t.Errorf("Expected 2 calls in flight at most, but got %v", maxInFlight)
+	}
+}
+
+func TestLimiter_AcquireFreesUnsentAttempts(t *testing.T) {
+	limiter := New("users", Config{Algorithm: AIMD(1, 0.5), InitialLimit: 4})
+
+	release, err := limiter.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release(nil, errors.New("breaker: circuit open"))
+	if limit := limiter.Limit(); limit != 4 {
+		t.Errorf("Expected an unsent attempt to keep the limit, but got %v", limit)
+	}
+	if inFlight := limiter.InFlight(); inFlight != 0 {
+		t.Errorf("Expected no request in flight, but got %v", inFlight)
+	}
+}
+
+func TestLimiter_AcquireCall(t *testing.T) {
+	limiter := New("users", Config{Algorithm: AIMD(1, 0.5), InitialLimit: 4})
+
+	err := caller.InParallelCallsLimited(context.Background(), limiter, callerFunc(func() error {
+		return &caller.StatusError{StatusCode: 404}
+	}))
+	if limit := limiter.Limit(); limit != 4 {
+		t.Errorf("Expected a 404 not to be a drop, but got limit %v", limit)
+	}
+	if err == nil {
+		t.Error("Expected the call error, but got nil")
+	}
+
+	caller.InParallelCallsLimited(context.Background(), limiter, callerFunc(func() error {
+		return &caller.StatusError{StatusCode: 503}
+	}))
+	if limit := limiter.Limit(); limit != 2 {
+		t.Errorf("Expected a 503 to be a drop, but got limit %v", limit)
+	}
+	if inFlight := limiter.InFlight(); inFlight != 0 {
+		t.Errorf("Expected no call in flight, but got %v", inFlight)
+	}
+}
+
+func TestLimiter_GuardsRestCaller(t *testing.T) {
+	limiter := New("users", Config{Algorithm: AIMD(1, 0.5), InitialLimit: 8, MinLimit: 1})
+	statusCode := http.StatusNotFound
+	request := builder.Get(&mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			return mock.NewJsonResponse(statusCode, map[string]string{})
+		},
+	}, "http://test/users")
+	call := func() {
+		caller.NewRestCaller(request, nil, caller.DefaultRetryPolicy(), 0, caller.ConstantBackOff(0)).
+			Guard(limiter).
+			ExecuteCall()
+	}
+
+	for i := 0; i < 5; i++ {
+		call()
+	}
+	if limit := limiter.Limit(); limit != 8 {
+		t.Errorf("Expected 404 responses to keep the limit, but got %v", limit)
+	}
+	statusCode = http.StatusServiceUnavailable
+	call()
+	if limit := limiter.Limit(); limit != 4 {
+		t.Errorf("Expected a 503 response to reduce the limit, but got %v", limit)
+	}
+}
@@ -0,0 +1,295 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+)
+
+type State int
+
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+type ErrCircuitOpen struct {
+	Name string
+}
+
+func (e *ErrCircuitOpen) Error() string {
+	return fmt.Sprintf("circuit breaker %s is open", e.Name)
+}
+
+type Config struct {
+	// Window is the rolling window where failures are counted, 10s by default,
+	// divided in Buckets, 10 by default.
+	Window  time.Duration
+	Buckets int
+	// MinRequests in the window before the failure ratio can open the circuit, 20 by default.
+	MinRequests int
+	// FailureRatio opens the circuit, 0.5 by default.
+	FailureRatio float64
+	// Cooldown is how long the circuit stays open before allowing trials, 30s by default.
+	Cooldown time.Duration
+	// TrialRequests allowed while half-open. All of them must succeed to close the circuit.
+	TrialRequests int
+	// IsFailure defaults to DefaultIsFailure.
+	IsFailure     func(statusCode int, err error) bool
+	OnStateChange func(name string, from State, to State)
+	Now           func() time.Time
+}
+
+func (config Config) withDefaults() Config {
+	if config.Window == 0 {
+		config.Window = 10 * time.Second
+	}
+	if config.Buckets == 0 {
+		config.Buckets = 10
+	}
+	if config.MinRequests == 0 {
+		config.MinRequests = 20
+	}
+	if config.FailureRatio == 0 {
+		config.FailureRatio = 0.5
+	}
+	if config.Cooldown == 0 {
+		config.Cooldown = 30 * time.Second
+	}
+	if config.TrialRequests == 0 {
+		config.TrialRequests = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = DefaultIsFailure
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return config
+}
+
+// validate rejects a Window not longer than Buckets nanoseconds, as the
+// buckets would have no duration.
+func (config Config) validate() error {
+	if config.Buckets < 0 || config.Window < time.Duration(config.Buckets) {
+		return fmt.Errorf("breaker: invalid window %v with %d buckets", config.Window, config.Buckets)
+	}
+	return nil
+}
+
+// DefaultIsFailure counts transport errors, 429 and 5xx. Requests cancelled by
+// the caller, such as the losing hedged request, are not failures.
+func DefaultIsFailure(statusCode int, err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	return (err != nil && statusCode == 0) || statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+type bucket struct {
+	start     time.Time
+	successes int
+	failures  int
+}
+
+type CircuitBreaker struct {
+	name           string
+	config         Config
+	mutex          sync.Mutex
+	state          State
+	generation     uint64
+	openedAt       time.Time
+	buckets        []bucket
+	trials         int
+	trialSuccesses int
+}
+
+// New returns an error when Window is not longer than Buckets nanoseconds, as
+// the buckets would have no duration.
+func New(name string, config Config) (*CircuitBreaker, error) {
+	config = config.withDefaults()
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return newCircuitBreaker(name, config), nil
+}
+
+func newCircuitBreaker(name string, config Config) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:    name,
+		config:  config,
+		buckets: make([]bucket, config.Buckets),
+	}
+}
+
+func (cb *CircuitBreaker) Name() string {
+	return cb.name
+}
+
+func (cb *CircuitBreaker) State() State {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	if cb.state == Open && cb.config.Now().Sub(cb.openedAt) >= cb.config.Cooldown {
+		return HalfOpen
+	}
+	return cb.state
+}
+
+// Allow returns ErrCircuitOpen or a function that must be called with the
+// outcome of the allowed request.
+func (cb *CircuitBreaker) Allow() (func(failure bool), error) {
+	generation, err := cb.allow()
+	if err != nil {
+		return nil, err
+	}
+	var once sync.Once
+	return func(failure bool) {
+		once.Do(func() { cb.record(generation, failure) })
+	}, nil
+}
+
+// Acquire lets a CircuitBreaker guard the attempts of a restCaller.
+func (cb *CircuitBreaker) Acquire(ctx context.Context) (func(resp *builder.Response, err error), error) {
+	generation, err := cb.allow()
+	if err != nil {
+		return nil, err
+	}
+	return func(resp *builder.Response, err error) {
+		if resp == nil {
+			cb.release(generation)
+			return
+		}
+		cb.record(generation, cb.config.IsFailure(resp.StatusCode, resp.Error))
+	}, nil
+}
+
+func (cb *CircuitBreaker) allow() (uint64, error) {
+	cb.mutex.Lock()
+	state, change := cb.currentState(cb.config.Now())
+	rejected := state == Open || (state == HalfOpen && cb.trials >= cb.config.TrialRequests)
+	if !rejected && state == HalfOpen {
+		cb.trials++
+	}
+	generation := cb.generation
+	cb.mutex.Unlock()
+	cb.notify(change)
+	if rejected {
+		return 0, &ErrCircuitOpen{Name: cb.name}
+	}
+	return generation, nil
+}
+
+// release gives back a permit whose request was never sent, without counting it.
+func (cb *CircuitBreaker) release(generation uint64) {
+	cb.mutex.Lock()
+	if generation == cb.generation && cb.state == HalfOpen && cb.trials > 0 {
+		cb.trials--
+	}
+	cb.mutex.Unlock()
+}
+
+type stateChange struct {
+	from State
+	to   State
+}
+
+// currentState moves an open circuit to half-open once the cooldown elapsed.
+func (cb *CircuitBreaker) currentState(now time.Time) (State, *stateChange) {
+	if cb.state == Open && now.Sub(cb.openedAt) >= cb.config.Cooldown {
+		change := cb.setState(HalfOpen, now)
+		return cb.state, change
+	}
+	return cb.state, nil
+}
+
+func (cb *CircuitBreaker) setState(state State, now time.Time) *stateChange {
+	change := &stateChange{from: cb.state, to: state}
+	cb.state = state
+	cb.generation++
+	cb.trials, cb.trialSuccesses = 0, 0
+	switch state {
+	case Open:
+		cb.openedAt = now
+	case Closed:
+		cb.buckets = make([]bucket, cb.config.Buckets)
+	}
+	return change
+}
+
+func (cb *CircuitBreaker) record(generation uint64, failure bool) {
+	cb.mutex.Lock()
+	if generation != cb.generation {
+		cb.mutex.Unlock()
+		return
+	}
+	now := cb.config.Now()
+	var change *stateChange
+	switch cb.state {
+	case Closed:
+		cb.count(now, failure)
+		if successes, failures := cb.totals(now); successes+failures >= cb.config.MinRequests &&
+			float64(failures)/float64(successes+failures) >= cb.config.FailureRatio {
+			change = cb.setState(Open, now)
+		}
+	case HalfOpen:
+		if failure {
+			change = cb.setState(Open, now)
+		} else if cb.trialSuccesses++; cb.trialSuccesses >= cb.config.TrialRequests {
+			change = cb.setState(Closed, now)
+		}
+	}
+	cb.mutex.Unlock()
+	cb.notify(change)
+}
+
+func (cb *CircuitBreaker) bucketDuration() time.Duration {
+	return cb.config.Window / time.Duration(cb.config.Buckets)
+}
+
+func (cb *CircuitBreaker) count(now time.Time, failure bool) {
+	start := now.Truncate(cb.bucketDuration())
+	b := &cb.buckets[(start.UnixNano()/int64(cb.bucketDuration()))%int64(len(cb.buckets))]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start}
+	}
+	if failure {
+		b.failures++
+	} else {
+		b.successes++
+	}
+}
+
+func (cb *CircuitBreaker) totals(now time.Time) (successes int, failures int) {
+	oldest := now.Add(-cb.config.Window)
+	for _, b := range cb.buckets {
+		if b.start.After(oldest) {
+			successes += b.successes
+			failures += b.failures
+		}
+	}
+	return successes, failures
+}
+
+func (cb *CircuitBreaker) notify(change *stateChange) {
+	if change != nil && cb.config.OnStateChange != nil {
+		cb.config.OnStateChange(cb.name, change.from, change.to)
+	}
+}
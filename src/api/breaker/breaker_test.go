@@ -0,0 +1,241 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+	"github.com/JuanAller/request-builder/src/api/caller"
+	"github.com/JuanAller/request-builder/src/api/mock"
+)
+
+type transition struct {
+	from State
+	to   State
+}
+
+func newTestBreaker(t *testing.T, clock *mock.Clock, transitions *[]transition) *CircuitBreaker {
+	cb, err := New("dependency", Config{
+		Window:        10 * time.Second,
+		MinRequests:   4,
+		FailureRatio:  0.5,
+		Cooldown:      time.Minute,
+		TrialRequests: 2,
+		Now:           clock.Now,
+		OnStateChange: func(name string, from State, to State) {
+			*transitions = append(*transitions, transition{from, to})
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cb
+}
+
+func record(t *testing.T, cb *CircuitBreaker, failures ...bool) {
+	for _, failure := range failures {
+		done, err := cb.Allow()
+		if err != nil {
+			t.Fatalf("Not expected error %v", err)
+		}
+		done(failure)
+	}
+}
+
+func TestCircuitBreaker_StateMachine(t *testing.T) {
+	clock := mock.NewClock(time.Now())
+	var transitions []transition
+	cb := newTestBreaker(t, clock, &transitions)
+
+	record(t, cb, true, false, true)
+	if cb.State() != Closed {
+		t.Errorf("Expected closed below the minimum requests")
+	}
+	record(t, cb, false)
+	if cb.State() != Open {
+		t.Fatalf("Expected open at 50%% failures")
+	}
+	var openErr *ErrCircuitOpen
+	if _, err := cb.Allow(); !errors.As(err, &openErr) || openErr.Name != "dependency" {
+		t.Errorf("Expected ErrCircuitOpen, but got %v", err)
+	}
+
+	clock.Advance(time.Minute)
+	first, _ := cb.Allow()
+	second, _ := cb.Allow()
+	if _, err := cb.Allow(); err == nil {
+		t.Errorf("Expected a single pair of trial requests while half-open")
+	}
+	first(false)
+	if cb.State() != HalfOpen {
+		t.Errorf("Expected half-open until every trial succeeds")
+	}
+	second(true)
+	if cb.State() != Open {
+		t.Errorf("Expected open after a failed trial")
+	}
+
+	clock.Advance(time.Minute)
+	record(t, cb, false, false)
+	if cb.State() != Closed {
+		t.Errorf("Expected closed after successful trials")
+	}
+
+	expected := []transition{{Closed, Open}, {Open, HalfOpen}, {HalfOpen, Open}, {Open, HalfOpen}, {HalfOpen, Closed}}
+	if len(transitions) != len(expected) {
+		t.Fatalf("Expected transitions %v, but got %v", expected, transitions)
+	}
+	for i := range expected {
+		if transitions[i] != expected[i] {
+			t.Errorf("Expected transitions %v, but got %v", expected, transitions)
+		}
+	}
+}
+
+func TestCircuitBreaker_RollingWindow(t *testing.T) {
+	clock := mock.NewClock(time.Now())
+	var transitions []transition
+	cb := newTestBreaker(t, clock, &transitions)
+
+	record(t, cb, true, true, true)
+	clock.Advance(11 * time.Second)
+	record(t, cb, true, false, false)
+	if cb.State() != Closed {
+		t.Errorf("Expected failures outside the window to be forgotten")
+	}
+}
+
+func TestDefaultIsFailure(t *testing.T) {
+	cases := []struct {
+		statusCode int
+		err        error
+		expected   bool
+	}{
+		{statusCode: http.StatusOK},
+		{statusCode: http.StatusNotFound},
+		{statusCode: http.StatusTooManyRequests, expected: true},
+		{statusCode: http.StatusBadGateway, expected: true},
+		{err: errors.New("connection refused"), expected: true},
+		{err: &builder.TimeoutError{Err: context.DeadlineExceeded}, expected: true},
+		{err: fmt.Errorf("get: %w", context.Canceled)},
+	}
+	for _, c := range cases {
+		if failure := DefaultIsFailure(c.statusCode, c.err); failure != c.expected {
+			t.Errorf("%v %v : expected failure %v, but got %v", c.statusCode, c.err, c.expected, failure)
+		}
+	}
+}
+
+func TestNew_InvalidWindow(t *testing.T) {
+	if _, err := New("dependency", Config{Window: 5, Buckets: 10}); err == nil {
+		t.Errorf("Expected an error for buckets without duration")
+	}
+	if _, err := NewRegistry(Config{Window: 5, Buckets: 10}); err == nil {
+		t.Errorf("Expected an error for buckets without duration")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	calls := 0
+	client := &mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			calls++
+			return mock.NewJsonResponse(http.StatusServiceUnavailable, map[string]string{})
+		},
+	}
+	registry, err := NewRegistry(Config{MinRequests: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		builder.Get(client, "http://failing/items").Use(Middleware(registry, nil)).Execute(nil)
+	}
+	response := builder.Get(client, "http://failing/items").Use(Middleware(registry, nil)).Execute(nil)
+
+	var openErr *ErrCircuitOpen
+	if !errors.As(response.Error, &openErr) || openErr.Name != "failing" {
+		t.Errorf("Expected ErrCircuitOpen for the host, but got %v", response.Error)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls before opening, but got %v", calls)
+	}
+	if registry.Get("other").State() != Closed {
+		t.Errorf("Expected independent breakers per host")
+	}
+}
+
+func TestMiddleware_IgnoresLostHedges(t *testing.T) {
+	var calls int32
+	lost := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			ioutil.ReadAll(r.Body)
+			<-r.Context().Done()
+			close(lost)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	registry, err := NewRegistry(Config{
+		MinRequests: 1,
+		IsFailure: func(statusCode int, err error) bool {
+			return err != nil || statusCode >= 500
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hedger := builder.NewHedger(builder.HedgeConfig{Delay: 10 * time.Millisecond, Budget: 1})
+	var entity map[string]interface{}
+	response := builder.Get(http.DefaultClient, server.URL).
+		WithHedging(hedger).
+		Use(Middleware(registry, Named("items"))).
+		Execute(&entity)
+	if response.Error != nil || response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the hedged response, but got %v %v", response.StatusCode, response.Error)
+	}
+	<-lost
+	time.Sleep(50 * time.Millisecond)
+
+	cb := registry.Get("items")
+	if successes, failures := cb.totals(time.Now()); cb.State() != Closed || successes != 1 || failures != 0 {
+		t.Errorf("Expected only the winning request to be counted, but got %v %v successes %v failures", cb.State(), successes, failures)
+	}
+}
+
+func TestCircuitBreaker_GuardsRestCaller(t *testing.T) {
+	calls := 0
+	request := builder.Get(&mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			calls++
+			return mock.NewJsonResponse(http.StatusInternalServerError, map[string]string{})
+		},
+	}, "http://test/items")
+	cb, err := New("items", Config{MinRequests: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = caller.NewRestCaller(request, nil, caller.DefaultRetryPolicy(), 5, caller.ConstantBackOff(0)).
+		Guard(cb).
+		WithClock(mock.NewClock(time.Now())).
+		ExecuteCall()
+
+	var openErr *ErrCircuitOpen
+	if !errors.As(err, &openErr) {
+		t.Errorf("Expected ErrCircuitOpen, but got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected the breaker to stop retries after 2 calls, but got %v", calls)
+	}
+}
@@ -0,0 +1,65 @@
+package breaker
+
+import (
+	"net/http"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+	"github.com/JuanAller/request-builder/src/api/dependency"
+)
+
+// Registry creates a circuit breaker per dependency, sharing the same configuration.
+type Registry struct {
+	*dependency.Registry[*CircuitBreaker]
+}
+
+// NewRegistry returns an error when config is invalid, like New.
+func NewRegistry(config Config) (*Registry, error) {
+	config = config.withDefaults()
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &Registry{dependency.NewRegistry(func(name string) *CircuitBreaker {
+		return newCircuitBreaker(name, config)
+	})}, nil
+}
+
+// KeyFunc names the dependency of a request, as dependency.KeyFunc.
+type KeyFunc = dependency.KeyFunc
+
+func ByHost(request *http.Request) string {
+	return dependency.ByHost(request)
+}
+
+// Named keys every request to the same dependency.
+func Named(name string) KeyFunc {
+	return dependency.Named(name)
+}
+
+// Middleware rejects requests with ErrCircuitOpen while the breaker of their
+// key is open. A nil key defaults to ByHost. Hedged requests that
+// lost are not counted.
+func Middleware(registry *Registry, key KeyFunc) builder.Middleware {
+	if key == nil {
+		key = ByHost
+	}
+	return func(next builder.HttpClient) builder.HttpClient {
+		return builder.HttpClientFunc(func(request *http.Request) (*http.Response, error) {
+			cb := registry.Get(key(request))
+			generation, err := cb.allow()
+			if err != nil {
+				return nil, err
+			}
+			response, err := next.Do(request)
+			if builder.HedgeLost(request) {
+				cb.release(generation)
+				return response, err
+			}
+			statusCode := 0
+			if response != nil {
+				statusCode = response.StatusCode
+			}
+			cb.record(generation, cb.config.IsFailure(statusCode, err))
+			return response, err
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package builder
+
+import "net/http"
+
+type Authorizer interface {
+	Authorize(request *http.Request) error
+}
+
+// Challenger is implemented by authorizers that can answer a 401 response.
+// When Challenge returns true the request is built, authorized and sent once more.
+type Challenger interface {
+	Challenge(request *http.Request, response *http.Response) (retry bool, err error)
+}
+
+// Signer is applied last when a request is built, once the final URL, headers
+// and body bytes are known.
+type Signer interface {
+	Sign(request *http.Request, body []byte) error
+}
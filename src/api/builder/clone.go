@@ -0,0 +1,52 @@
+package builder
+
+// Clone returns a builder that can be changed and executed without affecting
+// this one. Bodies, authorizers, signers and the entities of WithStatusEntity
+// are shared, not copied.
+func (requestBuilder *requestBuilder) Clone() *requestBuilder {
+	clone := *requestBuilder
+	request := *requestBuilder.request
+	request.Headers = copyMap(request.Headers)
+	request.QueryParams = copyMap(request.QueryParams)
+	request.MarshalFuncs = copyMap(request.MarshalFuncs)
+	request.Signers = append([]Signer(nil), request.Signers...)
+	clone.request = &request
+	clone.unmarshalFunctions = copyMap(requestBuilder.unmarshalFunctions)
+	clone.compressionFunctions = copyMap(requestBuilder.compressionFunctions)
+	clone.middlewares = append([]Middleware(nil), requestBuilder.middlewares...)
+	clone.errs = append([]error(nil), requestBuilder.errs...)
+	if requestBuilder.statusEntities != nil {
+		clone.statusEntities = make(map[int]interface{}, len(requestBuilder.statusEntities))
+		for statusCode, entity := range requestBuilder.statusEntities {
+			clone.statusEntities[statusCode] = entity
+		}
+	}
+	if requestBuilder.logConfig != nil {
+		logConfig := *requestBuilder.logConfig
+		clone.logConfig = &logConfig
+	}
+	return &clone
+}
+
+// requestTemplate is a frozen builder. It can be shared between goroutines
+// to derive requests from it.
+type requestTemplate struct {
+	requestBuilder *requestBuilder
+}
+
+// Freeze returns a template with the current configuration of the builder.
+// Later changes to the builder do not affect it. Status entities would be
+// shared by the requests of the template, so a builder with them fails every
+// request derived from it with a ConfigError; set them on those requests.
+func (requestBuilder *requestBuilder) Freeze() *requestTemplate {
+	clone := requestBuilder.Clone()
+	if len(clone.statusEntities) > 0 {
+		clone.configError("status entities cannot be set on a template")
+	}
+	return &requestTemplate{requestBuilder: clone}
+}
+
+// New returns a builder with the configuration of the template.
+func (template *requestTemplate) New() *requestBuilder {
+	return template.requestBuilder.Clone()
+}
@@ -0,0 +1,87 @@
+package builder
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/JuanAller/request-builder/src/api/mock"
+)
+
+func TestRequestBuilder_Clone(t *testing.T) {
+	var request *http.Request
+	client := &mock.HttpClientMock{
+		MakeResponseFunction: func(r *http.Request) (*http.Response, error) {
+			request = r
+			return mock.NewJsonResponse(http.StatusOK, map[string]string{})
+		},
+	}
+	original := Get(client, "http://test/users/1").
+		WithHeader("X-Caller", "aCaller").
+		WithQueryParam("version", "1")
+	clone := original.Clone().
+		WithHeader("X-Caller", "aClone").
+		WithQueryParam("version", "2").
+		WithCustomJSONUnmarshal(func(data []byte, v interface{}) error { return nil }).
+		LogResponseBody()
+
+	original.Execute(&map[string]string{})
+	if request.URL.Path != "/users/1" || request.URL.RawQuery != "version=1" || request.Header.Get("X-Caller") != "aCaller" {
+		t.Errorf("Expected the original request to be unchanged, but got %v %v", request.URL, request.Header)
+	}
+	clone.Execute(&map[string]string{})
+	if request.URL.Path != "/users/1" || request.URL.RawQuery != "version=2" || request.Header.Get("X-Caller") != "aClone" {
+		t.Errorf("Unexpected cloned request %v %v", request.URL, request.Header)
+	}
+	if original.logConfig != nil {
+		t.Error("Expected the original log config to be unchanged")
+	}
+}
+
+func TestRequestBuilder_Freeze(t *testing.T) {
+	var mutex sync.Mutex
+	callers := make(map[string]string)
+	client := &mock.HttpClientMock{
+		MakeResponseFunction: func(r *http.Request) (*http.Response, error) {
+			mutex.Lock()
+			callers[r.URL.Query().Get("id")] = r.Header.Get("X-Caller")
+			mutex.Unlock()
+			return mock.NewJsonResponse(http.StatusOK, map[string]string{})
+		},
+	}
+	builder := Get(client, "http://test/users").WithHeader("X-Caller", "aCaller")
+	template := builder.Freeze()
+	builder.WithHeader("X-Caller", "changed")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			template.New().WithQueryParam("id", strconv.Itoa(i)).Execute(&map[string]string{})
+		}(i)
+	}
+	wg.Wait()
+
+	if len(callers) != 10 || callers["7"] != "aCaller" {
+		t.Errorf("Unexpected requests %v", callers)
+	}
+}
+
+func TestRequestBuilder_FreezeRejectsStatusEntities(t *testing.T) {
+	client := &mock.HttpClientMock{
+		MakeResponseFunction: func(r *http.Request) (*http.Response, error) {
+			t.Error("Expected no request to be sent")
+			return mock.NewJsonResponse(http.StatusOK, map[string]string{})
+		},
+	}
+	template := Get(client, "http://test/users").WithStatusEntity(http.StatusAccepted, &map[string]string{}).Freeze()
+
+	response := template.New().Execute(&map[string]string{})
+	var configErr *ConfigError
+	if !errors.As(response.Error, &configErr) {
+		t.Errorf("Expected ConfigError, but got %v", response.Error)
+	}
+}
@@ -0,0 +1,111 @@
+package builder
+
+import (
+	"strings"
+	"time"
+)
+
+// Config holds what every request to a service shares. Paths given to a
+// Client made from it are relative to BaseURL, unless they are absolute URLs.
+type Config struct {
+	HttpClient  HttpClient
+	BaseURL     string
+	Headers     map[string]string
+	QueryParams map[string]string
+	// ContentType is APPLICATIONJSON or APPLICATIONXML, JSON by default.
+	ContentType string
+	// Marshalers replace the encoding of request bodies of their content
+	// types, and Unmarshalers the decoding of responses.
+	Marshalers   map[string]func(v interface{}) ([]byte, error)
+	Unmarshalers map[string]func([]byte, interface{}) error
+	Authorizer   Authorizer
+	Middlewares  []Middleware
+	LogConfig    *LogConfig
+	Timeout      time.Duration
+}
+
+// client creates request builders from a Config. It is safe to share between
+// goroutines, as the builders get their own copy of the configuration.
+type client struct {
+	config Config
+}
+
+func NewClient(config Config) *client {
+	config.Headers = copyMap(config.Headers)
+	config.QueryParams = copyMap(config.QueryParams)
+	config.Marshalers = copyMap(config.Marshalers)
+	config.Unmarshalers = copyMap(config.Unmarshalers)
+	config.Middlewares = append([]Middleware(nil), config.Middlewares...)
+	if config.LogConfig != nil {
+		logConfig := *config.LogConfig
+		config.LogConfig = &logConfig
+	}
+	return &client{config: config}
+}
+
+func (c *client) Get(path string) *requestBuilder {
+	return c.newRequestBuilder(Get, path)
+}
+
+func (c *client) Post(path string) *requestBuilder {
+	return c.newRequestBuilder(Post, path)
+}
+
+func (c *client) Put(path string) *requestBuilder {
+	return c.newRequestBuilder(Put, path)
+}
+
+func (c *client) Delete(path string) *requestBuilder {
+	return c.newRequestBuilder(Delete, path)
+}
+
+func (c *client) newRequestBuilder(method func(client HttpClient, path string) *requestBuilder, path string) *requestBuilder {
+	requestBuilder := method(c.config.HttpClient, c.url(path))
+	switch c.config.ContentType {
+	case APPLICATIONJSON:
+		requestBuilder.WithJSONContentType()
+	case APPLICATIONXML:
+		requestBuilder.WithXMLContentType()
+	default:
+		if c.config.ContentType != "" {
+			requestBuilder.configError("unknown content type %q", c.config.ContentType)
+		}
+	}
+	for key, value := range c.config.Headers {
+		requestBuilder.WithHeader(key, value)
+	}
+	for key, value := range c.config.QueryParams {
+		requestBuilder.WithQueryParam(key, value)
+	}
+	for contentType, marshal := range c.config.Marshalers {
+		requestBuilder.request.MarshalFuncs[contentType] = marshal
+	}
+	for contentType, unmarshal := range c.config.Unmarshalers {
+		requestBuilder.unmarshalFunctions[contentType] = unmarshal
+	}
+	if c.config.Authorizer != nil {
+		requestBuilder.WithAuthorizer(c.config.Authorizer)
+	}
+	if c.config.LogConfig != nil {
+		requestBuilder.WithLogConfig(*c.config.LogConfig)
+	}
+	return requestBuilder.Use(c.config.Middlewares...).WithTimeout(c.config.Timeout)
+}
+
+func (c *client) url(path string) string {
+	if c.config.BaseURL == "" || strings.Contains(path, "://") {
+		return path
+	}
+	if path == "" {
+		return c.config.BaseURL
+	}
+	return strings.TrimSuffix(c.config.BaseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+func copyMap[V any](m map[string]V) map[string]V {
+	copied := make(map[string]V, len(m))
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,123 @@
+package builder
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/mock"
+)
+
+func TestClient(t *testing.T) {
+	var mutex sync.Mutex
+	requests := make(map[string]*http.Request)
+	middlewareCalls := 0
+	headers := map[string]string{"X-Caller": "aCaller"}
+	client := NewClient(Config{
+		HttpClient: &mock.HttpClientMock{
+			MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+				mutex.Lock()
+				requests[request.URL.Path] = request
+				mutex.Unlock()
+				return mock.NewJsonResponse(http.StatusOK, map[string]string{"name": "aName"})
+			},
+		},
+		BaseURL:     "http://test/api/",
+		Headers:     headers,
+		QueryParams: map[string]string{"version": "2"},
+		Middlewares: []Middleware{func(next HttpClient) HttpClient {
+			return HttpClientFunc(func(request *http.Request) (*http.Response, error) {
+				mutex.Lock()
+				middlewareCalls++
+				mutex.Unlock()
+				return next.Do(request)
+			})
+		}},
+		Timeout: time.Second,
+	})
+	headers["X-Caller"] = "changed"
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			entity := make(map[string]string)
+			response := client.Get("/users/"+strconv.Itoa(i)).
+				WithHeader("X-Request", strconv.Itoa(i)).
+				Execute(&entity)
+			if response.Error != nil || entity["name"] != "aName" {
+				t.Errorf("Unexpected response %v %v", response.Error, entity)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(requests) != 10 || middlewareCalls != 10 {
+		t.Fatalf("Expected 10 requests through the middleware, but got %v and %v", len(requests), middlewareCalls)
+	}
+	request := requests["/api/users/3"]
+	if request == nil {
+		t.Fatalf("Expected the path to be relative to the base URL, but got %v", requests)
+	}
+	if request.Header.Get("X-Caller") != "aCaller" || request.Header.Get("X-Request") != "3" {
+		t.Errorf("Unexpected headers %v", request.Header)
+	}
+	if request.URL.Query().Get("version") != "2" {
+		t.Errorf("Unexpected query %v", request.URL.RawQuery)
+	}
+	if _, ok := request.Context().Deadline(); !ok {
+		t.Error("Expected the request to have the client timeout")
+	}
+}
+
+func TestClient_Codecs(t *testing.T) {
+	var body []byte
+	client := NewClient(Config{
+		HttpClient: &mock.HttpClientMock{
+			MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+				body, _ = ioutil.ReadAll(request.Body)
+				return mock.NewJsonResponse(http.StatusOK, map[string]string{"name": "aName"})
+			},
+		},
+		Marshalers: map[string]func(v interface{}) ([]byte, error){
+			APPLICATIONJSON: func(v interface{}) ([]byte, error) {
+				return []byte(`{"encoded":true}`), nil
+			},
+		},
+		Unmarshalers: map[string]func([]byte, interface{}) error{
+			APPLICATIONJSON: func(data []byte, v interface{}) error {
+				v.(map[string]string)["decoded"] = "true"
+				return nil
+			},
+		},
+	})
+
+	entity := make(map[string]string)
+	response := client.Post("http://test/users").WithBody(map[string]string{"name": "aName"}).Execute(entity)
+	if response.Error != nil || string(body) != `{"encoded":true}` || entity["decoded"] != "true" {
+		t.Errorf("Expected the client codecs, but got %v %s %v", response.Error, body, entity)
+	}
+}
+
+func TestClient_URL(t *testing.T) {
+	cases := []struct {
+		baseURL  string
+		path     string
+		expected string
+	}{
+		{"http://test/api", "users", "http://test/api/users"},
+		{"http://test/api/", "/users", "http://test/api/users"},
+		{"http://test/api", "", "http://test/api"},
+		{"http://test/api", "http://other/users", "http://other/users"},
+		{"", "http://other/users", "http://other/users"},
+	}
+	for _, c := range cases {
+		if url := NewClient(Config{BaseURL: c.baseURL}).url(c.path); url != c.expected {
+			t.Errorf("Expected %v for %v and %v, but got %v", c.expected, c.baseURL, c.path, url)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package builder
+
+import "net/http"
+
+// WithSuccessStatus decodes the responses with the given status codes too,
+// besides the ones from 200 to 299. WithDecodeOn replaces them instead.
+func (requestBuilder *requestBuilder) WithSuccessStatus(statusCodes ...int) *requestBuilder {
+	success := make(map[int]bool, len(statusCodes))
+	for _, statusCode := range statusCodes {
+		success[statusCode] = true
+	}
+	return requestBuilder.WithDecodeOn(func(statusCode int) bool {
+		return statusCode >= 200 && statusCode < 300 || success[statusCode]
+	})
+}
+
+// WithDecodeOn decodes the responses whose status code matches predicate
+// instead of the ones from 200 to 299.
+func (requestBuilder *requestBuilder) WithDecodeOn(predicate func(statusCode int) bool) *requestBuilder {
+	requestBuilder.decodeOn = predicate
+	return requestBuilder
+}
+
+// WithStatusEntity decodes the responses with statusCode into entity instead
+// of the entity given to Execute.
+func (requestBuilder *requestBuilder) WithStatusEntity(statusCode int, entity interface{}) *requestBuilder {
+	if requestBuilder.statusEntities == nil {
+		requestBuilder.statusEntities = make(map[int]interface{})
+	}
+	requestBuilder.statusEntities[statusCode] = entity
+	return requestBuilder
+}
+
+// entityFor returns where the response with statusCode is decoded, or false
+// when it is not decoded. 204 and 304 responses have no body to decode, and
+// execute leaves the entity unchanged, without error, for empty bodies of any
+// other status.
+func (requestBuilder *requestBuilder) entityFor(statusCode int, entityResponse interface{}) (interface{}, bool) {
+	if statusCode == http.StatusNoContent || statusCode == http.StatusNotModified {
+		return nil, false
+	}
+	if entity, ok := requestBuilder.statusEntities[statusCode]; ok {
+		return entity, true
+	}
+	if requestBuilder.decodeOn != nil {
+		return entityResponse, requestBuilder.decodeOn(statusCode)
+	}
+	return entityResponse, statusCode >= 200 && statusCode < 300
+}
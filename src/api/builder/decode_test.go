@@ -0,0 +1,96 @@
+package builder
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/JuanAller/request-builder/src/api/mock"
+)
+
+func newStatusClient(statusCode int, body string) HttpClient {
+	return &mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: statusCode,
+				Header:     http.Header{"Content-Type": {APPLICATIONJSON}},
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+			}, nil
+		},
+	}
+}
+
+type decodeUser struct {
+	Name string `json:"name"`
+}
+
+type decodeJob struct {
+	ID string `json:"id"`
+}
+
+func TestRequestBuilder_SuccessCriteria(t *testing.T) {
+	failing := func(data []byte, v interface{}) error {
+		t.Errorf("Expected no unmarshal of %q", data)
+		return nil
+	}
+
+	user := decodeUser{}
+	response := Get(newStatusClient(http.StatusNotFound, `{"name":"aName"}`), "http://test/users/1").Execute(&user)
+	if response.Error != nil || user.Name != "" {
+		t.Errorf("Expected 404 not to be decoded by default, but got %v %v", response.Error, user)
+	}
+
+	response = Get(newStatusClient(http.StatusNotFound, ``), "http://test/users/1").
+		WithSuccessStatus(http.StatusOK, http.StatusNotFound).
+		Execute(&user)
+	if response.Error != nil || user.Name != "" {
+		t.Errorf("Expected 404 to decode into an empty entity, but got %v %v", response.Error, user)
+	}
+
+	response = Get(newStatusClient(http.StatusNotFound, `{"name":"aName"}`), "http://test/users/1").
+		WithSuccessStatus(http.StatusOK, http.StatusNotFound).
+		Execute(&user)
+	if response.Error != nil || user.Name != "aName" {
+		t.Errorf("Expected 404 to be decoded, but got %v %v", response.Error, user)
+	}
+
+	user = decodeUser{}
+	response = Get(newStatusClient(http.StatusOK, `{"name":"aName"}`), "http://test/users/1").
+		WithSuccessStatus(http.StatusNotFound).
+		Execute(&user)
+	if response.Error != nil || user.Name != "aName" {
+		t.Errorf("Expected 200 to be decoded along with 404, but got %v %v", response.Error, user)
+	}
+
+	response = Get(newStatusClient(http.StatusNotModified, ``), "http://test/users/1").
+		WithDecodeOn(func(statusCode int) bool { return statusCode < 400 }).
+		WithCustomJSONUnmarshal(failing).
+		Execute(&user)
+	if response.Error != nil || response.StatusCode != http.StatusNotModified {
+		t.Errorf("Expected 304 to succeed without a body, but got %v", response.Error)
+	}
+
+	response = Delete(newStatusClient(http.StatusNoContent, `{}`), "http://test/users/1").
+		WithCustomJSONUnmarshal(failing).
+		Execute(nil)
+	if response.Error != nil {
+		t.Errorf("Expected 204 not to be decoded, but got %v", response.Error)
+	}
+
+	response = Get(newStatusClient(http.StatusOK, ``), "http://test/users/1").Execute(nil)
+	if response.Error != nil {
+		t.Errorf("Expected an empty body not to be decoded, but got %v", response.Error)
+	}
+}
+
+func TestRequestBuilder_WithStatusEntity(t *testing.T) {
+	user := decodeUser{}
+	job := decodeJob{}
+	response := Post(newStatusClient(http.StatusAccepted, `{"id":"aJob"}`), "http://test/users").
+		WithStatusEntity(http.StatusAccepted, &job).
+		Execute(&user)
+	if response.Error != nil || job.ID != "aJob" || user.Name != "" {
+		t.Errorf("Expected 202 to decode into the job, but got %v %v %v", response.Error, job, user)
+	}
+}
@@ -0,0 +1,221 @@
+package builder
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+	stale     bool
+	count     int
+}
+
+type digestAuthorizer struct {
+	username string
+	password string
+	mutex    sync.Mutex
+	// challenges caches the last challenge per host, so requests authorized
+	// after the first round-trip are sent without a new 401.
+	challenges map[string]*digestChallenge
+}
+
+// NewDigestAuthorizer returns an authorizer that answers Digest challenges.
+// Sharing it between builders with WithAuthorizer lets them reuse the nonce
+// of the last challenge instead of getting a 401 each.
+func NewDigestAuthorizer(username string, password string) *digestAuthorizer {
+	return &digestAuthorizer{
+		username:   username,
+		password:   password,
+		challenges: make(map[string]*digestChallenge),
+	}
+}
+
+func (requestBuilder *requestBuilder) WithDigestAuthorization(username string, password string) *requestBuilder {
+	return requestBuilder.WithAuthorizer(NewDigestAuthorizer(username, password))
+}
+
+func (digest *digestAuthorizer) Authorize(request *http.Request) error {
+	digest.mutex.Lock()
+	challenge, ok := digest.challenges[request.URL.Host]
+	if !ok {
+		digest.mutex.Unlock()
+		return nil
+	}
+	challenge.count++
+	current := *challenge
+	digest.mutex.Unlock()
+
+	authorization, err := digest.authorization(request, &current)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", authorization)
+	return nil
+}
+
+func (digest *digestAuthorizer) Challenge(request *http.Request, response *http.Response) (bool, error) {
+	var challenge *digestChallenge
+	for _, header := range response.Header.Values("WWW-Authenticate") {
+		candidate := parseDigestChallenge(header)
+		if candidate == nil || digestHash(candidate.algorithm) == nil {
+			continue
+		}
+		if challenge == nil || strings.HasPrefix(strings.ToUpper(candidate.algorithm), "SHA-256") {
+			challenge = candidate
+		}
+	}
+	if challenge == nil {
+		return false, nil
+	}
+	sent := parseDigestParams(strings.TrimPrefix(request.Header.Get("Authorization"), "Digest "))
+	if sent["nonce"] == challenge.nonce && !challenge.stale {
+		return false, nil
+	}
+
+	digest.mutex.Lock()
+	digest.challenges[request.URL.Host] = challenge
+	digest.mutex.Unlock()
+	return true, nil
+}
+
+func (digest *digestAuthorizer) authorization(request *http.Request, challenge *digestChallenge) (string, error) {
+	newHash := digestHash(challenge.algorithm)
+	cnonceBytes := make([]byte, 16)
+	if _, err := rand.Read(cnonceBytes); err != nil {
+		return "", err
+	}
+	cnonce := hex.EncodeToString(cnonceBytes)
+	count := fmt.Sprintf("%08x", challenge.count)
+	uri := request.URL.RequestURI()
+
+	ha1 := digestSum(newHash, digest.username, challenge.realm, digest.password)
+	if strings.HasSuffix(strings.ToLower(challenge.algorithm), "-sess") {
+		ha1 = digestSum(newHash, ha1, challenge.nonce, cnonce)
+	}
+	ha2 := digestSum(newHash, request.Method, uri)
+	response := digestSum(newHash, ha1, challenge.nonce, ha2)
+	if challenge.qop != "" {
+		response = digestSum(newHash, ha1, challenge.nonce, count, cnonce, challenge.qop, ha2)
+	}
+
+	authorization := fmt.Sprintf(`Digest username=%s, realm=%s, nonce=%s, uri=%s, algorithm=%s, response=%s`,
+		quoteDigest(digest.username), quoteDigest(challenge.realm), quoteDigest(challenge.nonce),
+		quoteDigest(uri), challenge.algorithm, quoteDigest(response))
+	if challenge.qop != "" {
+		authorization += fmt.Sprintf(`, qop=%s, nc=%s, cnonce=%s`, challenge.qop, count, quoteDigest(cnonce))
+	}
+	if challenge.opaque != "" {
+		authorization += fmt.Sprintf(`, opaque=%s`, quoteDigest(challenge.opaque))
+	}
+	return authorization, nil
+}
+
+func parseDigestChallenge(header string) *digestChallenge {
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Digest ") {
+		return nil
+	}
+	params := parseDigestParams(header[7:])
+	challenge := &digestChallenge{
+		realm:     params["realm"],
+		nonce:     params["nonce"],
+		opaque:    params["opaque"],
+		algorithm: params["algorithm"],
+		stale:     strings.EqualFold(params["stale"], "true"),
+	}
+	if challenge.nonce == "" {
+		return nil
+	}
+	if challenge.algorithm == "" {
+		challenge.algorithm = "MD5"
+	}
+	if qop, ok := params["qop"]; ok {
+		for _, option := range strings.Split(qop, ",") {
+			if strings.TrimSpace(option) == "auth" {
+				challenge.qop = "auth"
+			}
+		}
+		if challenge.qop == "" {
+			return nil
+		}
+	}
+	return challenge
+}
+
+func parseDigestParams(s string) map[string]string {
+	params := make(map[string]string)
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimLeft(s, ", ") {
+		equal := strings.IndexByte(s, '=')
+		if equal < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:equal]))
+		s = strings.TrimSpace(s[equal+1:])
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			var ok bool
+			if value, s, ok = unquoteDigest(s); !ok {
+				break
+			}
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			value, s = strings.TrimSpace(s[:end]), s[end:]
+		}
+		params[key] = value
+	}
+	return params
+}
+
+// quoteDigest returns value as a quoted-string, escaping quotes and backslashes.
+func quoteDigest(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// unquoteDigest reads the quoted-string at the start of s and returns its
+// value and the rest of s.
+func unquoteDigest(s string) (string, string, bool) {
+	var value strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i++; i < len(s) {
+				value.WriteByte(s[i])
+			}
+		case '"':
+			return value.String(), s[i+1:], true
+		default:
+			value.WriteByte(s[i])
+		}
+	}
+	return "", "", false
+}
+
+func digestHash(algorithm string) func() hash.Hash {
+	switch strings.TrimSuffix(strings.ToUpper(algorithm), "-SESS") {
+	case "MD5":
+		return md5.New
+	case "SHA-256":
+		return sha256.New
+	}
+	return nil
+}
+
+func digestSum(newHash func() hash.Hash, parts ...string) string {
+	h := newHash()
+	h.Write([]byte(strings.Join(parts, ":")))
+	return hex.EncodeToString(h.Sum(nil))
+}
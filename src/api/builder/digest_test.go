@@ -0,0 +1,113 @@
+package builder
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type digestServer struct {
+	*httptest.Server
+	algorithm  string
+	challenges int
+	counts     []string
+}
+
+func newDigestServer(t *testing.T, algorithm string) *digestServer {
+	server := &digestServer{algorithm: algorithm}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := parseDigestParams(strings.TrimPrefix(r.Header.Get("Authorization"), "Digest "))
+		newHash := digestHash(algorithm)
+		ha1 := digestSum(newHash, "admin", "appliance", "secret")
+		ha2 := digestSum(newHash, r.Method, r.URL.RequestURI())
+		expected := digestSum(newHash, ha1, "a-nonce", params["nc"], params["cnonce"], "auth", ha2)
+		if params["nonce"] != "a-nonce" || params["response"] != expected || params["opaque"] != "an-opaque" {
+			server.challenges++
+			w.Header().Add("WWW-Authenticate", `Basic realm="appliance"`)
+			w.Header().Add("WWW-Authenticate", fmt.Sprintf(`Digest realm="appliance", qop="auth,auth-int", nonce="a-nonce", opaque="an-opaque", algorithm=%s`, algorithm))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		server.counts = append(server.counts, params["nc"])
+		w.Header().Set("Content-Type", APPLICATIONJSON)
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	return server
+}
+
+func TestWithDigestAuthorization(t *testing.T) {
+	for _, algorithm := range []string{"MD5", "SHA-256"} {
+		t.Run(algorithm, func(t *testing.T) {
+			server := newDigestServer(t, algorithm)
+			defer server.Close()
+
+			authorizer := NewDigestAuthorizer("admin", "secret")
+			for i := 0; i < 2; i++ {
+				responseMap := make(map[string]string)
+				response := Get(http.DefaultClient, server.URL+"/status?verbose=true").
+					WithAuthorizer(authorizer).
+					Execute(&responseMap)
+				if err := checkRespFuncs(checkStatusCode(http.StatusOK), checkNotError())(response); err != nil {
+					t.Fatal(err)
+				}
+				if responseMap["status"] != "ok" {
+					t.Errorf("Expected ok")
+				}
+			}
+			if server.challenges != 1 {
+				t.Errorf("Expected a single challenge, but got : %v ", server.challenges)
+			}
+			if strings.Join(server.counts, ",") != "00000001,00000002" {
+				t.Errorf("Expected increasing nonce counts, but got : %v ", server.counts)
+			}
+		})
+	}
+}
+
+func TestWithDigestAuthorization_WrongPassword(t *testing.T) {
+	server := newDigestServer(t, "MD5")
+	defer server.Close()
+
+	response := Get(http.DefaultClient, server.URL+"/wrong").
+		WithDigestAuthorization("admin", "wrong").
+		Execute(nil)
+
+	if err := checkRespFuncs(checkStatusCode(http.StatusUnauthorized), checkNotError())(response); err != nil {
+		t.Error(err)
+	}
+	if server.challenges != 2 {
+		t.Errorf("Expected 2 challenges, but got : %v ", server.challenges)
+	}
+}
+
+func TestWithDigestAuthorization_ChallengesNotShared(t *testing.T) {
+	server := newDigestServer(t, "MD5")
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		response := Get(http.DefaultClient, server.URL+"/status").
+			WithDigestAuthorization("admin", "secret").
+			Execute(nil)
+		if err := checkRespFuncs(checkStatusCode(http.StatusOK))(response); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if server.challenges != 2 {
+		t.Errorf("Expected a challenge per authorizer, but got : %v ", server.challenges)
+	}
+}
+
+func TestDigestAuthorizer_EscapesQuotedValues(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodGet, "http://test/status", nil)
+	authorizer := NewDigestAuthorizer(`ad"min\`, "secret")
+	authorization, err := authorizer.authorization(request, &digestChallenge{realm: `a "realm"`, nonce: "a-nonce", algorithm: "MD5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := parseDigestParams(strings.TrimPrefix(authorization, "Digest "))
+	if params["username"] != `ad"min\` || params["realm"] != `a "realm"` || params["nonce"] != "a-nonce" {
+		t.Errorf("Expected escaped values to round-trip, but got : %v ", authorization)
+	}
+}
@@ -0,0 +1,58 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+// TimeoutError is returned when a request or a call is abandoned because its
+// deadline expired.
+type TimeoutError struct {
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	return "request timed out: " + e.Err.Error()
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+// IsTimeout reports whether err is a TimeoutError or any other timeout from the network.
+func IsTimeout(err error) bool {
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func timeoutError(ctx context.Context, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		if _, ok := err.(*TimeoutError); !ok {
+			return &TimeoutError{Err: err}
+		}
+	}
+	return err
+}
+
+// ConfigError is a mistake in the configuration of a builder. Execute returns
+// every ConfigError of the builder joined, without sending the request.
+type ConfigError struct {
+	Err error
+}
+
+func (e *ConfigError) Error() string {
+	return "invalid request configuration: " + e.Err.Error()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,28 @@
+package builder
+
+import (
+	"fmt"
+	"net/url"
+)
+
+func formMarshal(v interface{}) ([]byte, error) {
+	switch body := v.(type) {
+	case nil:
+		return nil, nil
+	case url.Values:
+		return []byte(body.Encode()), nil
+	case map[string][]string:
+		return []byte(url.Values(body).Encode()), nil
+	case map[string]string:
+		values := url.Values{}
+		for key, value := range body {
+			values.Set(key, value)
+		}
+		return []byte(values.Encode()), nil
+	case string:
+		return []byte(body), nil
+	case []byte:
+		return body, nil
+	}
+	return nil, fmt.Errorf("unsupported form body type %T", v)
+}
@@ -0,0 +1,217 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrHedgeLost is the cause of the cancellation of a hedged request whose
+// twin succeeded first.
+var ErrHedgeLost = errors.New("hedged request lost")
+
+// HedgeLost reports whether request was cancelled because its hedged twin
+// succeeded first. Its outcome says nothing about the dependency, so
+// middlewares counting failures or latencies ignore it.
+func HedgeLost(request *http.Request) bool {
+	return errors.Is(context.Cause(request.Context()), ErrHedgeLost)
+}
+
+type HedgeConfig struct {
+	// Delay before sending the hedged request. When it is 0, the delay is the
+	// Percentile of the latencies observed by the Hedger.
+	Delay time.Duration
+	// Percentile of the latency, 0.95 by default.
+	Percentile float64
+	// Samples is the number of recent latencies kept, 100 by default. No
+	// request is hedged until a tenth of them are observed.
+	Samples int
+	// Budget is the ratio of hedged requests to requests, 0.1 by default.
+	Budget float64
+	// MaxTokens bounds the hedges that can be saved while requests are fast, 10 by default.
+	MaxTokens float64
+}
+
+// Hedger sends a second request for idempotent requests that take longer than
+// the hedge delay and keeps the first successful response. It is meant to be
+// shared by the builders of the same service.
+type Hedger struct {
+	config    HedgeConfig
+	mutex     sync.Mutex
+	latencies []time.Duration
+	next      int
+	tokens    float64
+}
+
+func NewHedger(config HedgeConfig) *Hedger {
+	if config.Percentile <= 0 {
+		config.Percentile = 0.95
+	}
+	if config.Samples <= 0 {
+		config.Samples = 100
+	}
+	if config.Budget <= 0 {
+		config.Budget = 0.1
+	}
+	if config.MaxTokens <= 0 {
+		config.MaxTokens = 10
+	}
+	return &Hedger{config: config}
+}
+
+// WithHedging hedges GET, HEAD and OPTIONS requests with hedger.
+func (requestBuilder *requestBuilder) WithHedging(hedger *Hedger) *requestBuilder {
+	requestBuilder.hedger = hedger
+	return requestBuilder
+}
+
+// delay returns false when the request must not be hedged.
+func (h *Hedger) delay() (time.Duration, bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.tokens = math.Min(h.config.MaxTokens, h.tokens+h.config.Budget)
+	if h.config.Delay > 0 {
+		return h.config.Delay, true
+	}
+	if len(h.latencies) < int(math.Max(1, float64(h.config.Samples)/10)) {
+		return 0, false
+	}
+	latencies := append([]time.Duration(nil), h.latencies...)
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	index := int(math.Ceil(h.config.Percentile*float64(len(latencies)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	return latencies[index], true
+}
+
+func (h *Hedger) withdraw() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.tokens < 1 {
+		return false
+	}
+	h.tokens--
+	return true
+}
+
+func (h *Hedger) observe(latency time.Duration) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if len(h.latencies) < h.config.Samples {
+		h.latencies = append(h.latencies, latency)
+		return
+	}
+	h.latencies[h.next] = latency
+	h.next = (h.next + 1) % h.config.Samples
+}
+
+func hedgeable(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+type hedgeResult struct {
+	request  *http.Request
+	response *http.Response
+	err      error
+	latency  time.Duration
+	index    int
+	cancel   context.CancelFunc
+}
+
+func (result hedgeResult) succeeded() bool {
+	return result.err == nil && result.response.StatusCode < http.StatusInternalServerError
+}
+
+// hedgedSend rebuilds and sends the request again when the first one takes
+// longer than the hedge delay. The response that loses is cancelled with
+// ErrHedgeLost.
+func (requestBuilder *requestBuilder) hedgedSend(ctx context.Context) (*http.Request, *http.Response, error) {
+	hedger := requestBuilder.hedger
+	delay, ok := hedger.delay()
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelCauseFunc
+	launch := func() {
+		attemptCtx, cancel := context.WithCancelCause(ctx)
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			start := time.Now()
+			request, response, err := requestBuilder.send(attemptCtx)
+			results <- hedgeResult{request, response, err, time.Since(start), index, func() { cancel(nil) }}
+		}()
+	}
+
+	launch()
+	var timeout <-chan time.Time
+	if ok {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	pending := 1
+	var failed hedgeResult
+	for pending > 0 {
+		select {
+		case <-timeout:
+			timeout = nil
+			if hedger.withdraw() {
+				launch()
+				pending++
+			}
+		case result := <-results:
+			pending--
+			if result.succeeded() {
+				hedger.observe(result.latency)
+				for index, cancel := range cancels {
+					if index != result.index {
+						cancel(ErrHedgeLost)
+					}
+				}
+				go discardHedges(results, pending)
+				result.response.Body = &cancelOnClose{result.response.Body, result.cancel}
+				return result.request, result.response, nil
+			}
+			if failed.cancel != nil {
+				failed.discard()
+			}
+			failed = result
+		}
+	}
+	if failed.response == nil {
+		failed.cancel()
+	} else {
+		failed.response.Body = &cancelOnClose{failed.response.Body, failed.cancel}
+	}
+	return failed.request, failed.response, failed.err
+}
+
+func (result hedgeResult) discard() {
+	if result.response != nil {
+		io.Copy(ioutil.Discard, result.response.Body)
+		result.response.Body.Close()
+	}
+	result.cancel()
+}
+
+func discardHedges(results <-chan hedgeResult, pending int) {
+	for ; pending > 0; pending-- {
+		(<-results).discard()
+	}
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (body *cancelOnClose) Close() error {
+	defer body.cancel()
+	return body.ReadCloser.Close()
+}
@@ -0,0 +1,78 @@
+package builder
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequestBuilder_WithHedging(t *testing.T) {
+	var calls int32
+	cancelled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			ioutil.ReadAll(r.Body)
+			<-r.Context().Done()
+			close(cancelled)
+			return
+		}
+		w.Header().Set("Content-Type", APPLICATIONJSON)
+		w.Write([]byte(`{"name":"hedged"}`))
+	}))
+	defer server.Close()
+
+	hedger := NewHedger(HedgeConfig{Delay: 10 * time.Millisecond, Budget: 1})
+	entity := make(map[string]string)
+	response := Get(http.DefaultClient, server.URL).WithHedging(hedger).Execute(&entity)
+
+	if response.Error != nil || entity["name"] != "hedged" {
+		t.Fatalf("Expected the hedged response, but got %v %v", response.Error, entity)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the slow request to be cancelled")
+	}
+}
+
+func TestRequestBuilder_WithHedgingBudget(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", APPLICATIONJSON)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	hedger := NewHedger(HedgeConfig{Delay: time.Millisecond, Budget: 0.5})
+	for i := 0; i < 4; i++ {
+		entity := make(map[string]string)
+		if response := Get(http.DefaultClient, server.URL).WithHedging(hedger).Execute(&entity); response.Error != nil {
+			t.Fatal(response.Error)
+		}
+	}
+	post := Post(http.DefaultClient, server.URL).WithHedging(hedger)
+	post.Execute(&map[string]string{})
+
+	time.Sleep(50 * time.Millisecond)
+	if calls := atomic.LoadInt32(&calls); calls != 7 {
+		t.Errorf("Expected 2 hedges out of 4 requests and no hedge for POST, but got %v calls", calls)
+	}
+}
+
+func TestHedger_PercentileDelay(t *testing.T) {
+	hedger := NewHedger(HedgeConfig{Percentile: 0.9, Samples: 20})
+	if _, ok := hedger.delay(); ok {
+		t.Error("Expected no hedging before latencies are observed")
+	}
+	for i := 1; i <= 30; i++ {
+		hedger.observe(time.Duration(i) * time.Millisecond)
+	}
+	if delay, ok := hedger.delay(); !ok || delay != 28*time.Millisecond {
+		t.Errorf("Expected the 90th percentile of the last 20 latencies, but got %v", delay)
+	}
+}
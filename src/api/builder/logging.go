@@ -0,0 +1,301 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	redacted           = "[REDACTED]"
+	defaultMaxBodySize = 4096
+)
+
+var DefaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+type LogConfig struct {
+	// Logger defaults to slog.Default().
+	Logger *slog.Logger
+	// LogHeaders logs the request and response headers, redacted with RedactHeaders.
+	LogHeaders      bool
+	LogRequestBody  bool
+	LogResponseBody bool
+	// RedactHeaders defaults to DefaultRedactedHeaders.
+	RedactHeaders     []string
+	RedactQueryParams []string
+	// RedactBodyFields are redacted at any depth of JSON bodies and in form bodies.
+	RedactBodyFields []string
+	// MaxBodySize truncates logged bodies, 4096 bytes by default. Negative disables truncation.
+	MaxBodySize int
+	// Levels per outcome default to Info for 1xx-3xx, Warn for 4xx and Error for 5xx and transport errors.
+	SuccessLevel     slog.Leveler
+	ClientErrorLevel slog.Leveler
+	ServerErrorLevel slog.Leveler
+	ErrorLevel       slog.Leveler
+}
+
+func (requestBuilder *requestBuilder) logging() *LogConfig {
+	if requestBuilder.logConfig == nil {
+		requestBuilder.logConfig = &LogConfig{}
+	}
+	return requestBuilder.logConfig
+}
+
+func (requestBuilder *requestBuilder) WithLogger(logger *slog.Logger) *requestBuilder {
+	requestBuilder.logging().Logger = logger
+	return requestBuilder
+}
+
+func (requestBuilder *requestBuilder) WithLogConfig(config LogConfig) *requestBuilder {
+	requestBuilder.logConfig = &config
+	return requestBuilder
+}
+
+// URLTemplate returns the URL of request without userinfo nor query, with the
+// path segments that look like identifiers replaced by {id}, so requests to
+// the same endpoint share it.
+func URLTemplate(request *http.Request) string {
+	segments := strings.Split(request.URL.EscapedPath(), "/")
+	for i, segment := range segments {
+		if identifier(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	path := strings.Join(segments, "/")
+	if request.URL.Host == "" {
+		return path
+	}
+	return request.URL.Scheme + "://" + request.URL.Host + path
+}
+
+// identifier matches numbers, UUIDs and hex strings of 16 characters or more.
+func identifier(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	digits, hex := true, 0
+	for _, c := range segment {
+		switch {
+		case c >= '0' && c <= '9':
+			hex++
+		case c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F':
+			digits = false
+			hex++
+		case c == '-':
+			digits = false
+		default:
+			return false
+		}
+	}
+	return digits || hex >= 16 && (hex == len(segment) || hex == 32 && len(segment) == 36)
+}
+
+func logMiddleware(config *LogConfig) Middleware {
+	return func(next HttpClient) HttpClient {
+		if config == nil {
+			return next
+		}
+		logger := config.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		redactor := newRedactor(config)
+		return HttpClientFunc(func(request *http.Request) (*http.Response, error) {
+			attrs := []slog.Attr{
+				slog.String("method", request.Method),
+				slog.String("url", redactor.url(request.URL)),
+				slog.String("url_template", URLTemplate(request)),
+				slog.Int64("request_size", request.ContentLength),
+			}
+			if config.LogHeaders {
+				attrs = append(attrs, slog.Any("request_headers", redactor.headers(request.Header)))
+			}
+			if config.LogRequestBody {
+				if body := requestBody(request); body != nil {
+					attrs = append(attrs, slog.String("request_body", redactor.body(body, request.Header.Get("Content-Type"))))
+				}
+			}
+
+			start := time.Now()
+			response, err := next.Do(request)
+			attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+			if timing, ok := RequestTiming(request); ok {
+				attrs = append(attrs, slog.Group("timing",
+					slog.Duration("dns", timing.DNS),
+					slog.Duration("connect", timing.Connect),
+					slog.Duration("tls", timing.TLSHandshake),
+					slog.Duration("ttfb", timing.TimeToFirstByte),
+					slog.Duration("total", timing.Total),
+					slog.Bool("connection_reused", timing.ConnectionReused)))
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+				logger.LogAttrs(request.Context(), levelOr(config.ErrorLevel, slog.LevelError), "http request failed", attrs...)
+				return response, err
+			}
+
+			responseSize := response.ContentLength
+			attrs = append(attrs, slog.Int("status", response.StatusCode))
+			if config.LogHeaders {
+				attrs = append(attrs, slog.Any("response_headers", redactor.headers(response.Header)))
+			}
+			if config.LogResponseBody {
+				body, readErr := ioutil.ReadAll(response.Body)
+				response.Body.Close()
+				response.Body = ioutil.NopCloser(bytes.NewReader(body))
+				if readErr != nil {
+					return response, readErr
+				}
+				responseSize = int64(len(body))
+				if compressionType(response) == "gzip" {
+					if uncompressed, err := gzipAlgorithm(body); err == nil {
+						body = uncompressed
+					}
+				}
+				attrs = append(attrs, slog.String("response_body", redactor.body(body, response.Header.Get("Content-Type"))))
+			}
+			attrs = append(attrs, slog.Int64("response_size", responseSize))
+			logger.LogAttrs(request.Context(), config.level(response.StatusCode), "http request", attrs...)
+			return response, nil
+		})
+	}
+}
+
+func (config *LogConfig) level(statusCode int) slog.Level {
+	switch {
+	case statusCode >= 500:
+		return levelOr(config.ServerErrorLevel, slog.LevelError)
+	case statusCode >= 400:
+		return levelOr(config.ClientErrorLevel, slog.LevelWarn)
+	}
+	return levelOr(config.SuccessLevel, slog.LevelInfo)
+}
+
+func levelOr(leveler slog.Leveler, defaultLevel slog.Level) slog.Level {
+	if leveler == nil {
+		return defaultLevel
+	}
+	return leveler.Level()
+}
+
+func requestBody(request *http.Request) []byte {
+	if request.GetBody == nil {
+		return nil
+	}
+	body, err := request.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+	data, _ := ioutil.ReadAll(body)
+	return data
+}
+
+type redactor struct {
+	headerNames map[string]bool
+	queryParams map[string]bool
+	bodyFields  map[string]bool
+	maxBodySize int
+}
+
+func newRedactor(config *LogConfig) *redactor {
+	headers := config.RedactHeaders
+	if headers == nil {
+		headers = DefaultRedactedHeaders
+	}
+	maxBodySize := config.MaxBodySize
+	if maxBodySize == 0 {
+		maxBodySize = defaultMaxBodySize
+	}
+	return &redactor{
+		headerNames: lowerSet(headers),
+		queryParams: lowerSet(config.RedactQueryParams),
+		bodyFields:  lowerSet(config.RedactBodyFields),
+		maxBodySize: maxBodySize,
+	}
+}
+
+func lowerSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[strings.ToLower(value)] = true
+	}
+	return set
+}
+
+// url hides the password of the URL, like the redacted query params.
+func (r *redactor) url(u *url.URL) string {
+	if len(r.queryParams) == 0 || u.RawQuery == "" {
+		return u.Redacted()
+	}
+	redactedURL := *u
+	redactedURL.RawQuery = r.values(u.Query(), r.queryParams).Encode()
+	return redactedURL.Redacted()
+}
+
+func (r *redactor) values(values url.Values, fields map[string]bool) url.Values {
+	result := url.Values{}
+	for key, list := range values {
+		if fields[strings.ToLower(key)] {
+			result[key] = []string{redacted}
+			continue
+		}
+		result[key] = list
+	}
+	return result
+}
+
+func (r *redactor) headers(header http.Header) map[string]string {
+	result := make(map[string]string, len(header))
+	for key, values := range header {
+		if r.headerNames[strings.ToLower(key)] {
+			result[key] = redacted
+			continue
+		}
+		result[key] = strings.Join(values, ", ")
+	}
+	return result
+}
+
+func (r *redactor) body(body []byte, contentType string) string {
+	if len(r.bodyFields) > 0 {
+		switch {
+		case contentType == "" || strings.Contains(contentType, "json"):
+			var decoded interface{}
+			if json.Unmarshal(body, &decoded) == nil {
+				body, _ = json.Marshal(r.jsonValue(decoded))
+			}
+		case strings.HasPrefix(contentType, APPLICATIONFORM):
+			if values, err := url.ParseQuery(string(body)); err == nil {
+				body = []byte(r.values(values, r.bodyFields).Encode())
+			}
+		}
+	}
+	if r.maxBodySize > 0 && len(body) > r.maxBodySize {
+		return string(body[:r.maxBodySize]) + "...(truncated)"
+	}
+	return string(body)
+}
+
+func (r *redactor) jsonValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, field := range typed {
+			if r.bodyFields[strings.ToLower(key)] {
+				typed[key] = redacted
+				continue
+			}
+			typed[key] = r.jsonValue(field)
+		}
+	case []interface{}:
+		for i, item := range typed {
+			typed[i] = r.jsonValue(item)
+		}
+	}
+	return value
+}
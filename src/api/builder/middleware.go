@@ -0,0 +1,52 @@
+package builder
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Middleware wraps the HttpClient used to send a request. It can inspect or
+// replace the built request, the raw response, or answer without calling next.
+type Middleware func(next HttpClient) HttpClient
+
+type HttpClientFunc func(request *http.Request) (*http.Response, error)
+
+func (f HttpClientFunc) Do(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+var globalMiddlewares struct {
+	sync.RWMutex
+	list []Middleware
+}
+
+// Use installs middlewares for every request builder. Global middlewares wrap
+// the ones installed per builder, and earlier middlewares wrap later ones.
+func Use(middlewares ...Middleware) {
+	globalMiddlewares.Lock()
+	globalMiddlewares.list = append(globalMiddlewares.list, middlewares...)
+	globalMiddlewares.Unlock()
+}
+
+func (requestBuilder *requestBuilder) Use(middlewares ...Middleware) *requestBuilder {
+	requestBuilder.middlewares = append(requestBuilder.middlewares, middlewares...)
+	return requestBuilder
+}
+
+func chain(client HttpClient, middlewares []Middleware) HttpClient {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		client = middlewares[i](client)
+	}
+	return client
+}
+
+func (requestBuilder *requestBuilder) chainedClient() HttpClient {
+	client := chain(requestBuilder.client, []Middleware{
+		logMiddleware(requestBuilder.logConfig),
+		timingMiddleware,
+	})
+	client = chain(client, requestBuilder.middlewares)
+	globalMiddlewares.RLock()
+	defer globalMiddlewares.RUnlock()
+	return chain(client, globalMiddlewares.list)
+}
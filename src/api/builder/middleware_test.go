@@ -0,0 +1,78 @@
+package builder
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/JuanAller/request-builder/src/api/mock"
+)
+
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next HttpClient) HttpClient {
+		return HttpClientFunc(func(request *http.Request) (*http.Response, error) {
+			*calls = append(*calls, name+":request")
+			request.Header.Add("X-Chain", name)
+			response, err := next.Do(request)
+			if err == nil {
+				*calls = append(*calls, name+":"+response.Status)
+			}
+			return response, err
+		})
+	}
+}
+
+func TestRequestBuilder_Use(t *testing.T) {
+	var calls []string
+	Use(recordingMiddleware("global", &calls))
+	defer func() { globalMiddlewares.list = nil }()
+
+	responseMap := make(map[string]string)
+	response := Get(&mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			chain := request.Header.Values("X-Chain")
+			if len(chain) != 3 || chain[0] != "global" || chain[1] != "first" || chain[2] != "second" {
+				return mock.NewJsonResponse(http.StatusBadRequest, chain)
+			}
+			return mock.NewJsonResponse(http.StatusOK, map[string]string{"name": "aName"})
+		},
+	}, "http://test/middleware").
+		Use(recordingMiddleware("first", &calls), recordingMiddleware("second", &calls)).
+		Execute(&responseMap)
+
+	if err := checkRespFuncs(checkStatusCode(http.StatusOK), checkNotError())(response); err != nil {
+		t.Error(err)
+	}
+	expected := []string{"global:request", "first:request", "second:request", "second:200", "first:200", "global:200"}
+	if len(calls) != len(expected) {
+		t.Fatalf("Expected calls %v, but got : %v ", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("Expected calls %v, but got : %v ", expected, calls)
+		}
+	}
+}
+
+func TestRequestBuilder_UseShortCircuit(t *testing.T) {
+	cached := func(next HttpClient) HttpClient {
+		return HttpClientFunc(func(request *http.Request) (*http.Response, error) {
+			return mock.NewJsonResponse(http.StatusOK, map[string]string{"name": "cached"})
+		})
+	}
+	responseMap := make(map[string]string)
+	response := Get(&mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			t.Errorf("Not expected call to the client")
+			return nil, nil
+		},
+	}, "http://test/short_circuit").
+		Use(cached).
+		Execute(&responseMap)
+
+	if err := checkRespFuncs(checkStatusCode(http.StatusOK), checkNotError())(response); err != nil {
+		t.Error(err)
+	}
+	if responseMap["name"] != "cached" {
+		t.Errorf("Expected cached")
+	}
+}
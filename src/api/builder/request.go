@@ -4,20 +4,21 @@ import (
 	"net/http"
 	"encoding/json"
 	"bytes"
-	"net/http/httputil"
-	"log"
 	"encoding/xml"
+	"context"
 )
 
 type request struct {
-	Method         string
-	Path           string
-	Headers        map[string]string
-	QueryParams    map[string]string
-	Body           interface{}
-	MarshalFuncs   map[string]func(v interface{}) ([]byte, error)
-	ContentType    string
-	logRequestBody bool
+	Method       string
+	Path         string
+	Headers      map[string]string
+	QueryParams  map[string]string
+	Body         interface{}
+	MarshalFuncs map[string]func(v interface{}) ([]byte, error)
+	ContentType  string
+	Authorizer   Authorizer
+	Signers      []Signer
+	Context      context.Context
 }
 
 func newRequest(method string, path string) *request {
@@ -29,17 +30,22 @@ func newRequest(method string, path string) *request {
 		MarshalFuncs: map[string]func(v interface{}) ([]byte, error){
 			APPLICATIONJSON: json.Marshal,
 			APPLICATIONXML:  xml.Marshal,
+			APPLICATIONFORM: formMarshal,
 		},
 		ContentType: APPLICATIONJSON,
+		Context:     context.Background(),
 	}
 }
 
-func (request *request) build() (*http.Request, error) {
+func (request *request) build(ctx context.Context) (*http.Request, error) {
 	byteSlice, marshallErr := request.MarshalFuncs[request.ContentType](request.Body)
 	if marshallErr != nil {
-		return nil, marshallErr
+		return nil, &ConfigError{Err: marshallErr}
+	}
+	newRequest, err := http.NewRequestWithContext(ctx, request.Method, request.Path, bytes.NewBuffer(byteSlice))
+	if err != nil {
+		return nil, &ConfigError{Err: err}
 	}
-	newRequest, _ := http.NewRequest(request.Method, request.Path, bytes.NewBuffer(byteSlice))
 	query := newRequest.URL.Query()
 	for key, value := range request.QueryParams {
 		query.Add(key, value)
@@ -48,9 +54,36 @@ func (request *request) build() (*http.Request, error) {
 	for key, value := range request.Headers {
 		newRequest.Header.Set(key, value)
 	}
-	if request.logRequestBody {
-		rawRequest, _ := httputil.DumpRequestOut(newRequest, request.logRequestBody)
-		log.Println(string(rawRequest))
+	for key, value := range contextHeaders(ctx) {
+		newRequest.Header.Set(key, value)
+	}
+	if request.Authorizer != nil {
+		if err := request.Authorizer.Authorize(newRequest); err != nil {
+			return nil, err
+		}
+	}
+	for _, signer := range request.Signers {
+		if err := signer.Sign(newRequest, byteSlice); err != nil {
+			return nil, err
+		}
 	}
 	return newRequest, nil
 }
+
+type headersKey struct{}
+
+// ContextWithHeader makes the requests built with ctx send the header, over
+// the ones given to the builder.
+func ContextWithHeader(ctx context.Context, key string, value string) context.Context {
+	headers := map[string]string{}
+	for k, v := range contextHeaders(ctx) {
+		headers[k] = v
+	}
+	headers[key] = value
+	return context.WithValue(ctx, headersKey{}, headers)
+}
+
+func contextHeaders(ctx context.Context) map[string]string {
+	headers, _ := ctx.Value(headersKey{}).(map[string]string)
+	return headers
+}
@@ -3,13 +3,17 @@ package builder
 import (
 	"io/ioutil"
 	"encoding/base64"
-	"net/http/httputil"
-	"log"
+	"net/http"
+	"io"
+	"context"
+	"fmt"
+	"time"
 )
 
 const (
 	APPLICATIONJSON = "application/json"
 	APPLICATIONXML  = "application/xml"
+	APPLICATIONFORM = "application/x-www-form-urlencoded"
 )
 
 type unmarshalFunc func([]byte, interface{}) error
@@ -20,7 +24,14 @@ type requestBuilder struct {
 	contentType          string
 	unmarshalFunctions   map[string]func([]byte, interface{}) error
 	compressionFunctions map[string]compressionAlgorithm
-	logResponseBody      bool
+	logConfig            *LogConfig
+	middlewares          []Middleware
+	timing               bool
+	timeout              time.Duration
+	hedger               *Hedger
+	errs                 []error
+	decodeOn             func(statusCode int) bool
+	statusEntities       map[int]interface{}
 }
 
 func (requestBuilder *requestBuilder) WithQueryParam(key string, value string) *requestBuilder {
@@ -29,6 +40,11 @@ func (requestBuilder *requestBuilder) WithQueryParam(key string, value string) *
 }
 
 func (requestBuilder *requestBuilder) WithHeader(key string, value string) *requestBuilder {
+	if !validHeaderName(key) {
+		requestBuilder.configError("invalid header name %q", key)
+	} else if !validHeaderValue(value) {
+		requestBuilder.configError("invalid value for header %q", key)
+	}
 	requestBuilder.request.Headers[key] = value
 	return requestBuilder
 }
@@ -44,6 +60,10 @@ func (requestBuilder *requestBuilder) WithJSONContentType() *requestBuilder {
 }
 
 func (requestBuilder *requestBuilder) WithCustomJSONUnmarshal(custom unmarshalFunc) *requestBuilder {
+	if custom == nil {
+		requestBuilder.configError("nil JSON unmarshal function")
+		return requestBuilder
+	}
 	requestBuilder.unmarshalFunctions[APPLICATIONJSON] = custom
 	return requestBuilder
 }
@@ -55,10 +75,21 @@ func (requestBuilder *requestBuilder) WithXMLContentType() *requestBuilder {
 }
 
 func (requestBuilder *requestBuilder) WithCustomXMLUnmarshal(custom unmarshalFunc) *requestBuilder {
+	if custom == nil {
+		requestBuilder.configError("nil XML unmarshal function")
+		return requestBuilder
+	}
 	requestBuilder.unmarshalFunctions[APPLICATIONXML] = custom
 	return requestBuilder
 }
 
+// WithFormContentType encodes the body as a form. Responses are still decoded
+// with the previously selected content type, JSON by default.
+func (requestBuilder *requestBuilder) WithFormContentType() *requestBuilder {
+	requestBuilder.request.ContentType = APPLICATIONFORM
+	return requestBuilder.WithHeader("Content-Type", APPLICATIONFORM)
+}
+
 func (requestBuilder *requestBuilder) WithBody(body interface{}) *requestBuilder {
 	requestBuilder.request.Body = body
 	return requestBuilder
@@ -72,49 +103,145 @@ func (requestBuilder *requestBuilder) WithBasicAuthorization(username string, pa
 	return requestBuilder.WithHeader("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
 }
 
+func (requestBuilder *requestBuilder) WithAuthorizer(authorizer Authorizer) *requestBuilder {
+	requestBuilder.request.Authorizer = authorizer
+	return requestBuilder
+}
+
+func (requestBuilder *requestBuilder) WithSigner(signer Signer) *requestBuilder {
+	requestBuilder.request.Signers = append(requestBuilder.request.Signers, signer)
+	return requestBuilder
+}
+
+func (requestBuilder *requestBuilder) LogHeaders() *requestBuilder {
+	requestBuilder.logging().LogHeaders = true
+	return requestBuilder
+}
+
 func (requestBuilder *requestBuilder) LogResponseBody() *requestBuilder {
-	requestBuilder.logResponseBody = true
+	requestBuilder.logging().LogResponseBody = true
 	return requestBuilder
 }
 
 func (requestBuilder *requestBuilder) LogRequestBody() *requestBuilder {
-	requestBuilder.request.logRequestBody = true
+	requestBuilder.logging().LogRequestBody = true
+	return requestBuilder
+}
+
+func (requestBuilder *requestBuilder) WithContext(ctx context.Context) *requestBuilder {
+	requestBuilder.request.Context = ctx
+	return requestBuilder
+}
+
+// WithTimeout bounds the whole execution, including reading the response body.
+func (requestBuilder *requestBuilder) WithTimeout(timeout time.Duration) *requestBuilder {
+	requestBuilder.timeout = timeout
 	return requestBuilder
 }
 
 func (requestBuilder *requestBuilder) Execute(entityResponse interface{}) *Response {
-	request, err := requestBuilder.request.build()
-	if err != nil {
-		return &Response{
-			Error: err,
-		}
+	return requestBuilder.ExecuteWithContext(requestBuilder.request.Context, entityResponse)
+}
+
+// ExecuteWithContext sends the request with ctx instead of the one given to WithContext.
+func (requestBuilder *requestBuilder) ExecuteWithContext(ctx context.Context, entityResponse interface{}) *Response {
+	if err := requestBuilder.validate(); err != nil {
+		return &Response{Error: err}
 	}
-	response, err := requestBuilder.client.Do(request)
-	if err != nil {
-		return &Response{
-			Error: err,
+	if requestBuilder.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, requestBuilder.timeout)
+		defer cancel()
+	}
+	result := requestBuilder.execute(ctx, entityResponse)
+	if result.Request != nil {
+		if timing, ok := RequestTiming(result.Request); ok {
+			result.Timing = &timing
 		}
 	}
-	if requestBuilder.logResponseBody {
-		rawResp, _ := httputil.DumpResponse(response, requestBuilder.logResponseBody)
-		log.Println(string(rawResp))
+	return result
+}
+
+func (requestBuilder *requestBuilder) execute(ctx context.Context, entityResponse interface{}) *Response {
+	send := requestBuilder.send
+	if requestBuilder.hedger != nil && hedgeable(requestBuilder.request.Method) {
+		send = requestBuilder.hedgedSend
 	}
-	defer response.Body.Close()
-	if response.StatusCode >= 200 && response.StatusCode < 300 {
-		body, _ := ioutil.ReadAll(response.Body)
-		if body, err = requestBuilder.compressionFunctions[compressionType(response)](body); err != nil {
-			return &Response{
-				StatusCode: response.StatusCode,
-				Error:      err,
-			}
-		}
+	request, response, err := send(ctx)
+	if err != nil {
 		return &Response{
-			StatusCode: response.StatusCode,
-			Error:      requestBuilder.unmarshalFunctions[requestBuilder.contentType](body, entityResponse),
+			Error:   timeoutError(ctx, err),
+			Request: request,
 		}
 	}
-	return &Response{
+	defer response.Body.Close()
+	result := &Response{
 		StatusCode: response.StatusCode,
-		Error:      nil,
+		Header:     response.Header,
+		Request:    request,
+	}
+	entity, decode := requestBuilder.entityFor(response.StatusCode, entityResponse)
+	if !decode {
+		return result
+	}
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		result.Error = timeoutError(ctx, err)
+		return result
+	}
+	// An empty body is not an error: there is nothing to decode.
+	if len(body) == 0 {
+		return result
+	}
+	uncompress := requestBuilder.compressionFunctions[compressionType(response)]
+	if uncompress == nil {
+		result.Error = fmt.Errorf("unsupported Content-Encoding %q", compressionType(response))
+		return result
+	}
+	if body, err = uncompress(body); err != nil {
+		result.Error = err
+		return result
+	}
+	result.Error = requestBuilder.unmarshalFunctions[requestBuilder.contentType](body, entity)
+	return result
+}
+
+// build builds the request of each attempt, with its own timing trace when
+// the builder was configured WithTiming.
+func (requestBuilder *requestBuilder) build(ctx context.Context) (*http.Request, error) {
+	if requestBuilder.timing {
+		ctx = withTimingTrace(ctx, &timingTrace{})
+	}
+	return requestBuilder.request.build(ctx)
+}
+
+func (requestBuilder *requestBuilder) send(ctx context.Context) (*http.Request, *http.Response, error) {
+	client := requestBuilder.chainedClient()
+	request, err := requestBuilder.build(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return request, nil, err
+	}
+	challenger, ok := requestBuilder.request.Authorizer.(Challenger)
+	if !ok || response.StatusCode != http.StatusUnauthorized {
+		return request, response, nil
+	}
+	retry, err := challenger.Challenge(request, response)
+	if err != nil {
+		response.Body.Close()
+		return request, nil, err
+	}
+	if !retry {
+		return request, response, nil
+	}
+	io.Copy(ioutil.Discard, response.Body)
+	response.Body.Close()
+	if request, err = requestBuilder.build(ctx); err != nil {
+		return nil, nil, err
 	}
+	response, err = client.Do(request)
+	return request, response, err
 }
@@ -8,6 +8,8 @@ import (
 	"errors"
 	"encoding/json"
 	"encoding/xml"
+	"net/http/httptest"
+	"time"
 )
 
 type checkRequestFunc func(request *http.Request) error
@@ -73,7 +75,7 @@ func checkNotError() checkRespFunc {
 func checkErrorMessage(errMessage string) checkRespFunc {
 	return func(response *Response) error {
 		if errMessage != response.Error.Error() {
-			fmt.Errorf("Expected error message : %v, but got : %v ", errMessage, response.Error.Error())
+			return fmt.Errorf("Expected error message : %v, but got : %v ", errMessage, response.Error.Error())
 		}
 		return nil
 	}
@@ -383,3 +385,59 @@ func TestGetWithGZIPCompression(t *testing.T) {
 		t.Errorf("Expected ok")
 	}
 }
+
+func TestGetWithUnknownCompression(t *testing.T) {
+	responseMap := make(map[string]interface{})
+	response := Get(&mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			response, err := mock.NewJsonResponse(http.StatusOK, map[string]string{"status": "ok"})
+			response.Header.Set("Content-Encoding", "br")
+			return response, err
+		},
+	}, "http://test/get_with_br").
+		Execute(&responseMap)
+
+	if response.Error == nil {
+		t.Error("Expected an error for the unknown Content-Encoding")
+	}
+}
+
+func TestRequestBuilder_WithTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	responseMap := make(map[string]string)
+	response := Get(http.DefaultClient, server.URL).
+		WithTimeout(20 * time.Millisecond).
+		Execute(&responseMap)
+
+	var timeoutErr *TimeoutError
+	if !errors.As(response.Error, &timeoutErr) || !IsTimeout(response.Error) {
+		t.Errorf("Expected TimeoutError, but got : %v ", response.Error)
+	}
+}
+
+func TestRequestBuilder_ResponseHeadersAndRequest(t *testing.T) {
+	response := Post(&mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			response, err := mock.NewJsonResponse(http.StatusTooManyRequests, map[string]string{})
+			response.Header.Set("Retry-After", "10")
+			return response, err
+		},
+	}, "http://test/post_too_many_requests").
+		Execute(nil)
+
+	if err := checkRespFuncs(checkStatusCode(http.StatusTooManyRequests), checkNotError())(response); err != nil {
+		t.Error(err)
+	}
+	if response.Header.Get("Retry-After") != "10" {
+		t.Errorf("Expected Retry-After header")
+	}
+	if response.Request == nil || response.Request.Method != http.MethodPost {
+		t.Errorf("Expected the sent request")
+	}
+}
@@ -1,6 +1,14 @@
 package builder
 
+import "net/http"
+
 type Response struct {
 	StatusCode int
 	Error      error
+	Header     http.Header `xml:"-" json:"-"`
+	// Request is the last request sent, after any authorization challenge.
+	Request *http.Request `xml:"-" json:"-"`
+	// Timing is set when the request was built WithTiming. It is the timing
+	// of the request in Request.
+	Timing *Timing `xml:"-" json:"-"`
 }
@@ -0,0 +1,110 @@
+package builder
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+type Timing struct {
+	DNS              time.Duration
+	Connect          time.Duration
+	TLSHandshake     time.Duration
+	TimeToFirstByte  time.Duration
+	Total            time.Duration
+	ConnectionReused bool
+}
+
+type timingTrace struct {
+	mutex        sync.Mutex
+	timing       Timing
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+}
+
+type timingKey struct{}
+
+// RequestTiming returns the timing collected so far for a request built with WithTiming.
+func RequestTiming(request *http.Request) (Timing, bool) {
+	trace, ok := request.Context().Value(timingKey{}).(*timingTrace)
+	if !ok {
+		return Timing{}, false
+	}
+	return trace.snapshot(), true
+}
+
+func (requestBuilder *requestBuilder) WithTiming() *requestBuilder {
+	requestBuilder.timing = true
+	return requestBuilder
+}
+
+func withTimingTrace(ctx context.Context, trace *timingTrace) context.Context {
+	ctx = context.WithValue(ctx, timingKey{}, trace)
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			trace.update(func(now time.Time) {
+				if trace.start.IsZero() {
+					trace.start = now
+				}
+			})
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			trace.update(func(now time.Time) { trace.dnsStart = now })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			trace.update(func(now time.Time) { trace.timing.DNS = now.Sub(trace.dnsStart) })
+		},
+		ConnectStart: func(network, addr string) {
+			trace.update(func(now time.Time) { trace.connectStart = now })
+		},
+		ConnectDone: func(network, addr string, err error) {
+			trace.update(func(now time.Time) { trace.timing.Connect = now.Sub(trace.connectStart) })
+		},
+		TLSHandshakeStart: func() {
+			trace.update(func(now time.Time) { trace.tlsStart = now })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			trace.update(func(now time.Time) { trace.timing.TLSHandshake = now.Sub(trace.tlsStart) })
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			trace.update(func(now time.Time) { trace.timing.ConnectionReused = info.Reused })
+		},
+		GotFirstResponseByte: func() {
+			trace.update(func(now time.Time) { trace.timing.TimeToFirstByte = now.Sub(trace.start) })
+		},
+	})
+}
+
+func (trace *timingTrace) update(f func(now time.Time)) {
+	now := time.Now()
+	trace.mutex.Lock()
+	f(now)
+	trace.mutex.Unlock()
+}
+
+func (trace *timingTrace) snapshot() Timing {
+	trace.mutex.Lock()
+	defer trace.mutex.Unlock()
+	return trace.timing
+}
+
+// timingMiddleware is the innermost middleware, so the durations are complete
+// when the other middlewares get the response. Each attempt, hedge or
+// challenge retry is built with its own trace.
+func timingMiddleware(next HttpClient) HttpClient {
+	return HttpClientFunc(func(request *http.Request) (*http.Response, error) {
+		trace, ok := request.Context().Value(timingKey{}).(*timingTrace)
+		if !ok {
+			return next.Do(request)
+		}
+		trace.update(func(now time.Time) { trace.start = now })
+		response, err := next.Do(request)
+		trace.update(func(now time.Time) { trace.timing.Total = now.Sub(trace.start) })
+		return response, err
+	})
+}
@@ -0,0 +1,96 @@
+package builder
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequestBuilder_WithTiming(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", APPLICATIONJSON)
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+	var output bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&output, nil))
+
+	var responses []*Response
+	for i := 0; i < 2; i++ {
+		responseMap := make(map[string]string)
+		responses = append(responses, Get(server.Client(), server.URL).
+			WithTiming().
+			WithLogger(logger).
+			Execute(&responseMap))
+	}
+
+	first, second := responses[0], responses[1]
+	if err := checkRespFuncs(checkStatusCode(http.StatusOK), checkNotError())(first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Timing == nil || second.Timing == nil {
+		t.Fatalf("Expected timing in responses")
+	}
+	if first.Timing.ConnectionReused || first.Timing.Connect <= 0 || first.Timing.TLSHandshake <= 0 {
+		t.Errorf("Expected a new TLS connection, but got : %+v ", *first.Timing)
+	}
+	if first.Timing.TimeToFirstByte <= 0 || first.Timing.Total < first.Timing.TimeToFirstByte {
+		t.Errorf("Unexpected durations : %+v ", *first.Timing)
+	}
+	if !second.Timing.ConnectionReused || second.Timing.TLSHandshake != 0 {
+		t.Errorf("Expected a reused connection, but got : %+v ", *second.Timing)
+	}
+	if !strings.Contains(output.String(), "timing.connection_reused=true") {
+		t.Errorf("Expected timing in logs : %v ", output.String())
+	}
+}
+
+func TestRequestBuilder_WithoutTiming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	responseMap := make(map[string]string)
+	if response := Get(http.DefaultClient, server.URL).Execute(&responseMap); response.Timing != nil {
+		t.Errorf("Not expected timing")
+	}
+}
+
+func TestRequestBuilder_WithTimingReportsHedgeThatWon(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			ioutil.ReadAll(r.Body)
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", APPLICATIONJSON)
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	responseMap := make(map[string]string)
+	response := Get(http.DefaultClient, server.URL).
+		WithHedging(NewHedger(HedgeConfig{Delay: 50 * time.Millisecond, Budget: 1})).
+		WithTiming().
+		Execute(&responseMap)
+	elapsed := time.Since(start)
+
+	if response.Error != nil || response.Timing == nil {
+		t.Fatalf("Expected timing of the hedged response, but got %v", response.Error)
+	}
+	if response.Timing.TimeToFirstByte <= 0 || response.Timing.Total > elapsed-50*time.Millisecond {
+		t.Errorf("Expected the timing of the hedge only, but got : %+v in %v ", *response.Timing, elapsed)
+	}
+	if timing, _ := RequestTiming(response.Request); timing != *response.Timing {
+		t.Errorf("Expected the timing of the request that won, but got : %+v ", timing)
+	}
+}
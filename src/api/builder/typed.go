@@ -0,0 +1,14 @@
+package builder
+
+import "context"
+
+// Do executes the request decoding the response into a new T.
+func Do[T any](requestBuilder *requestBuilder) (T, *Response, error) {
+	return DoWithContext[T](requestBuilder.request.Context, requestBuilder)
+}
+
+func DoWithContext[T any](ctx context.Context, requestBuilder *requestBuilder) (T, *Response, error) {
+	var entity T
+	response := requestBuilder.ExecuteWithContext(ctx, &entity)
+	return entity, response, response.Error
+}
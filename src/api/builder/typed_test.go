@@ -0,0 +1,30 @@
+package builder
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/JuanAller/request-builder/src/api/mock"
+)
+
+type typedUser struct {
+	Name string `json:"name"`
+}
+
+func TestDo(t *testing.T) {
+	client := &mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			return mock.NewJsonResponse(http.StatusOK, map[string]string{"name": "aName"})
+		},
+	}
+
+	user, response, err := Do[typedUser](Get(client, "http://test/users/1"))
+	if err != nil || response.StatusCode != http.StatusOK || user.Name != "aName" {
+		t.Errorf("Unexpected result %v %v %v", user, response, err)
+	}
+
+	users, _, err := Do[map[string]string](Get(client, "http://test/users/1"))
+	if err != nil || users["name"] != "aName" {
+		t.Errorf("Unexpected result %v %v", users, err)
+	}
+}
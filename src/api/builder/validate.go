@@ -0,0 +1,54 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+func (requestBuilder *requestBuilder) configError(format string, args ...interface{}) {
+	requestBuilder.errs = append(requestBuilder.errs, &ConfigError{Err: fmt.Errorf(format, args...)})
+}
+
+// validate joins the errors found while configuring the builder with the ones
+// of the configuration it ends with.
+func (requestBuilder *requestBuilder) validate() error {
+	errs := append([]error(nil), requestBuilder.errs...)
+	request := requestBuilder.request
+	// A method is a token, like a header name.
+	if !validHeaderName(request.Method) {
+		errs = append(errs, &ConfigError{Err: fmt.Errorf("invalid method %q", request.Method)})
+	}
+	if _, err := url.Parse(request.Path); err != nil {
+		errs = append(errs, &ConfigError{Err: err})
+	}
+	if request.MarshalFuncs[request.ContentType] == nil {
+		errs = append(errs, &ConfigError{Err: fmt.Errorf("no marshal function for content type %q", request.ContentType)})
+	}
+	if requestBuilder.unmarshalFunctions[requestBuilder.contentType] == nil {
+		errs = append(errs, &ConfigError{Err: fmt.Errorf("no unmarshal function for content type %q", requestBuilder.contentType)})
+	}
+	return errors.Join(errs...)
+}
+
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if c >= 0x7f || c <= ' ' || strings.ContainsRune("\"(),/:;<=>?@[\\]{}", c) {
+			return false
+		}
+	}
+	return true
+}
+
+func validHeaderValue(value string) bool {
+	for _, c := range value {
+		if (c < ' ' && c != '\t') || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
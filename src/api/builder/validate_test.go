@@ -0,0 +1,59 @@
+package builder
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/JuanAller/request-builder/src/api/mock"
+)
+
+func TestRequestBuilder_ConfigErrors(t *testing.T) {
+	client := &mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			t.Error("Expected no request to be sent")
+			return mock.NewJsonResponse(http.StatusOK, map[string]string{})
+		},
+	}
+	response := Get(client, "http://test/users/1%zz").
+		WithHeader("X Caller", "aCaller").
+		WithHeader("X-Caller", "a\r\nvalue").
+		WithCustomJSONUnmarshal(nil).
+		Execute(&map[string]string{})
+
+	joined, ok := response.Error.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("Expected a joined error, but got %v", response.Error)
+	}
+	if errs := joined.Unwrap(); len(errs) != 4 {
+		t.Errorf("Expected 4 errors, but got %v", errs)
+	}
+	var configErr *ConfigError
+	if !errors.As(response.Error, &configErr) {
+		t.Errorf("Expected ConfigError, but got %v", response.Error)
+	}
+}
+
+func TestClient_UnknownContentType(t *testing.T) {
+	response := NewClient(Config{ContentType: "text/csv"}).Get("http://test/users").Execute(&map[string]string{})
+	var configErr *ConfigError
+	if !errors.As(response.Error, &configErr) {
+		t.Errorf("Expected ConfigError, but got %v", response.Error)
+	}
+}
+
+func TestRequestBuilder_InvalidMethod(t *testing.T) {
+	requestBuilder := Get(&mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			t.Error("Expected no request to be sent")
+			return mock.NewJsonResponse(http.StatusOK, map[string]string{})
+		},
+	}, "http://test/users")
+	requestBuilder.request.Method = "GET USERS"
+
+	response := requestBuilder.Execute(&map[string]string{})
+	var configErr *ConfigError
+	if !errors.As(response.Error, &configErr) {
+		t.Errorf("Expected ConfigError, but got %v", response.Error)
+	}
+}
@@ -0,0 +1,142 @@
+package bulkhead
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+)
+
+type Reason string
+
+const (
+	QueueFull    Reason = "queue_full"
+	QueueTimeout Reason = "queue_timeout"
+)
+
+type ErrBulkheadFull struct {
+	Name   string
+	Reason Reason
+}
+
+func (e *ErrBulkheadFull) Error() string {
+	return fmt.Sprintf("bulkhead %s rejected the request: %s", e.Name, e.Reason)
+}
+
+type Config struct {
+	// MaxConcurrent requests in flight, 10 by default.
+	MaxConcurrent int
+	// MaxQueue requests waiting for a slot. Requests are rejected as soon as
+	// every slot is taken when it is 0.
+	MaxQueue int
+	// QueueTimeout limits the time waiting for a slot, besides the context of the request.
+	QueueTimeout time.Duration
+}
+
+type Stats struct {
+	MaxConcurrent int
+	MaxQueue      int
+	InFlight      int
+	Waiting       int
+	Rejected      map[Reason]uint64
+}
+
+type Bulkhead struct {
+	name     string
+	config   Config
+	slots    chan struct{}
+	mutex    sync.Mutex
+	waiting  int
+	rejected map[Reason]uint64
+}
+
+func New(name string, config Config) *Bulkhead {
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = 10
+	}
+	return &Bulkhead{
+		name:     name,
+		config:   config,
+		slots:    make(chan struct{}, config.MaxConcurrent),
+		rejected: make(map[Reason]uint64),
+	}
+}
+
+func (b *Bulkhead) Name() string {
+	return b.name
+}
+
+func (b *Bulkhead) Stats() Stats {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	rejected := make(map[Reason]uint64, len(b.rejected))
+	for reason, count := range b.rejected {
+		rejected[reason] = count
+	}
+	return Stats{
+		MaxConcurrent: b.config.MaxConcurrent,
+		MaxQueue:      b.config.MaxQueue,
+		InFlight:      len(b.slots),
+		Waiting:       b.waiting,
+		Rejected:      rejected,
+	}
+}
+
+// Enter takes a slot, waiting in the queue when every slot is taken. The
+// returned release must be called once the request is done.
+func (b *Bulkhead) Enter(ctx context.Context) (func(), error) {
+	select {
+	case b.slots <- struct{}{}:
+		return b.release, nil
+	default:
+	}
+
+	b.mutex.Lock()
+	if b.waiting >= b.config.MaxQueue {
+		b.rejected[QueueFull]++
+		b.mutex.Unlock()
+		return nil, &ErrBulkheadFull{Name: b.name, Reason: QueueFull}
+	}
+	b.waiting++
+	b.mutex.Unlock()
+	defer func() {
+		b.mutex.Lock()
+		b.waiting--
+		b.mutex.Unlock()
+	}()
+
+	var timeout <-chan time.Time
+	if b.config.QueueTimeout > 0 {
+		timer := time.NewTimer(b.config.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case b.slots <- struct{}{}:
+		return b.release, nil
+	case <-timeout:
+		b.mutex.Lock()
+		b.rejected[QueueTimeout]++
+		b.mutex.Unlock()
+		return nil, &ErrBulkheadFull{Name: b.name, Reason: QueueTimeout}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (b *Bulkhead) release() {
+	<-b.slots
+}
+
+// Acquire lets a Bulkhead guard the attempts of a restCaller.
+func (b *Bulkhead) Acquire(ctx context.Context) (func(resp *builder.Response, err error), error) {
+	release, err := b.Enter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(resp *builder.Response, err error) {
+		release()
+	}, nil
+}
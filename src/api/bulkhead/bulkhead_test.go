@@ -0,0 +1,147 @@
+package bulkhead
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+	"github.com/JuanAller/request-builder/src/api/caller"
+	"github.com/JuanAller/request-builder/src/api/mock"
+)
+
+func TestBulkhead_QueueFull(t *testing.T) {
+	b := New("users", Config{MaxConcurrent: 1})
+	release, err := b.Enter(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = b.Enter(context.Background())
+	var full *ErrBulkheadFull
+	if !errors.As(err, &full) || full.Name != "users" || full.Reason != QueueFull {
+		t.Fatalf("expected a queue full rejection, got %v", err)
+	}
+	release()
+	if _, err := b.Enter(context.Background()); err != nil {
+		t.Fatalf("expected the released slot, got %v", err)
+	}
+	if stats := b.Stats(); stats.InFlight != 1 || stats.Rejected[QueueFull] != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestBulkhead_Queue(t *testing.T) {
+	b := New("users", Config{MaxConcurrent: 1, MaxQueue: 1, QueueTimeout: 20 * time.Millisecond})
+	release, _ := b.Enter(context.Background())
+
+	_, err := b.Enter(context.Background())
+	var full *ErrBulkheadFull
+	if !errors.As(err, &full) || full.Reason != QueueTimeout {
+		t.Fatalf("expected a queue timeout, got %v", err)
+	}
+
+	entered := make(chan error)
+	go func() {
+		_, err := b.Enter(context.Background())
+		entered <- err
+	}()
+	for b.Stats().Waiting != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := b.Enter(context.Background()); !errors.As(err, &full) || full.Reason != QueueFull {
+		t.Fatalf("expected the queue to be full, got %v", err)
+	}
+	release()
+	if err := <-entered; err != nil {
+		t.Fatalf("expected the queued request to enter, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := b.Enter(ctx); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var mutex sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mutex.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mutex.Lock()
+		inFlight--
+		mutex.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	registry := NewRegistry(Config{MaxConcurrent: 2, MaxQueue: 10})
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var entity map[string]interface{}
+			if response := builder.Get(http.DefaultClient, server.URL).Use(Middleware(registry, nil)).Execute(&entity); response.Error != nil {
+				t.Error(response.Error)
+			}
+		}()
+	}
+	wg.Wait()
+	if maxInFlight > 2 {
+		t.Fatalf("expected at most 2 requests in flight, got %v", maxInFlight)
+	}
+}
+
+func TestMiddleware_HoldsSlotUntilBodyClosed(t *testing.T) {
+	registry := NewRegistry(Config{MaxConcurrent: 1})
+	client := Middleware(registry, Named("users"))(builder.HttpClientFunc(func(request *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{}`))}, nil
+	}))
+	request, _ := http.NewRequest(http.MethodGet, "http://test/users", nil)
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inFlight := registry.Get("users").Stats().InFlight; inFlight != 1 {
+		t.Fatalf("expected the slot to be held while the body is read, got %v in flight", inFlight)
+	}
+	response.Body.Close()
+	response.Body.Close()
+	if inFlight := registry.Get("users").Stats().InFlight; inFlight != 0 {
+		t.Fatalf("expected the slot to be released once, got %v in flight", inFlight)
+	}
+}
+
+func TestBulkhead_GuardsRestCaller(t *testing.T) {
+	b := New("items", Config{MaxConcurrent: 1})
+	request := builder.Get(&mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			if stats := b.Stats(); stats.InFlight != 1 {
+				t.Errorf("Expected the attempt to hold a slot, but got %+v", stats)
+			}
+			return mock.NewJsonResponse(http.StatusOK, map[string]string{})
+		},
+	}, "http://test/items")
+
+	entity := make(map[string]string)
+	if err := caller.NewRestCaller(request, &entity, caller.FailOnError(), 0, caller.ConstantBackOff(0)).Guard(b).ExecuteCall(); err != nil {
+		t.Fatal(err)
+	}
+	if stats := b.Stats(); stats.InFlight != 0 {
+		t.Errorf("Expected the slot to be released, but got %+v", stats)
+	}
+}
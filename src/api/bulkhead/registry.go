@@ -0,0 +1,79 @@
+package bulkhead
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+	"github.com/JuanAller/request-builder/src/api/dependency"
+)
+
+// Registry creates a bulkhead per dependency, sharing the same configuration.
+type Registry struct {
+	*dependency.Registry[*Bulkhead]
+}
+
+func NewRegistry(config Config) *Registry {
+	return &Registry{dependency.NewRegistry(func(name string) *Bulkhead {
+		return New(name, config)
+	})}
+}
+
+func (r *Registry) Stats() map[string]Stats {
+	bulkheads := r.All()
+	stats := make(map[string]Stats, len(bulkheads))
+	for name, b := range bulkheads {
+		stats[name] = b.Stats()
+	}
+	return stats
+}
+
+// KeyFunc names the dependency of a request, as dependency.KeyFunc.
+type KeyFunc = dependency.KeyFunc
+
+func ByHost(request *http.Request) string {
+	return dependency.ByHost(request)
+}
+
+// Named keys every request to the same dependency.
+func Named(name string) KeyFunc {
+	return dependency.Named(name)
+}
+
+// Middleware holds a slot of the bulkhead of their key until the response
+// body is closed, rejecting requests with ErrBulkheadFull when it is
+// saturated. A nil key defaults to ByHost.
+func Middleware(registry *Registry, key KeyFunc) builder.Middleware {
+	if key == nil {
+		key = ByHost
+	}
+	return func(next builder.HttpClient) builder.HttpClient {
+		return builder.HttpClientFunc(func(request *http.Request) (*http.Response, error) {
+			release, err := registry.Get(key(request)).Enter(request.Context())
+			if err != nil {
+				return nil, err
+			}
+			response, err := next.Do(request)
+			if err != nil || response.Body == nil {
+				release()
+				return response, err
+			}
+			response.Body = &releaseOnClose{ReadCloser: response.Body, release: release}
+			return response, nil
+		})
+	}
+}
+
+// releaseOnClose gives back the slot of a response once its body is read and closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (body *releaseOnClose) Close() error {
+	err := body.ReadCloser.Close()
+	body.once.Do(body.release)
+	return err
+}
@@ -0,0 +1,101 @@
+package caller
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Random is the source of jitter. *rand.Rand satisfies it, so tests can pass
+// a seeded one to get deterministic delays.
+type Random interface {
+	Int63n(n int64) int64
+}
+
+type lockedRandom struct {
+	mutex  sync.Mutex
+	random Random
+}
+
+func (r *lockedRandom) Int63n(n int64) int64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.random.Int63n(n)
+}
+
+func newRandom(random Random) Random {
+	if random == nil {
+		random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return &lockedRandom{random: random}
+}
+
+// between returns a random duration in [min, max].
+func between(random Random, min time.Duration, max time.Duration) time.Duration {
+	if max <= min {
+		return min
+	}
+	return min + time.Duration(random.Int63n(int64(max-min)+1))
+}
+
+func capped(delay time.Duration, max time.Duration) time.Duration {
+	if max > 0 && (delay > max || delay < 0) {
+		return max
+	}
+	return delay
+}
+
+func ConstantBackOff(delay time.Duration) BackOffStrategy {
+	return func(retryNumber int) time.Duration {
+		return delay
+	}
+}
+
+func LinearBackOff(initial time.Duration, increment time.Duration, max time.Duration) BackOffStrategy {
+	return func(retryNumber int) time.Duration {
+		return capped(initial+time.Duration(retryNumber-1)*increment, max)
+	}
+}
+
+func ExponentialBackOff(initial time.Duration, multiplier float64, max time.Duration) BackOffStrategy {
+	return func(retryNumber int) time.Duration {
+		delay := float64(initial) * math.Pow(multiplier, float64(retryNumber-1))
+		if delay > math.MaxInt64 {
+			return capped(time.Duration(math.MaxInt64), max)
+		}
+		return capped(time.Duration(delay), max)
+	}
+}
+
+// FullJitterBackOff waits a random delay between zero and the capped exponential delay.
+func FullJitterBackOff(initial time.Duration, max time.Duration, random Random) BackOffStrategy {
+	exponential := ExponentialBackOff(initial, 2, max)
+	random = newRandom(random)
+	return func(retryNumber int) time.Duration {
+		return between(random, 0, exponential(retryNumber))
+	}
+}
+
+// EqualJitterBackOff waits half of the capped exponential delay plus a random part of the other half.
+func EqualJitterBackOff(initial time.Duration, max time.Duration, random Random) BackOffStrategy {
+	exponential := ExponentialBackOff(initial, 2, max)
+	random = newRandom(random)
+	return func(retryNumber int) time.Duration {
+		half := exponential(retryNumber) / 2
+		return half + between(random, 0, half)
+	}
+}
+
+// DecorrelatedJitterBackOff waits a random delay between initial and three
+// times the previous delay of the call, so each call needs its own strategy.
+func DecorrelatedJitterBackOff(initial time.Duration, max time.Duration, random Random) BackOffFactory {
+	random = newRandom(random)
+	return func() BackOffStrategy {
+		previous := initial
+		return func(retryNumber int) time.Duration {
+			previous = capped(between(random, initial, previous*3), max)
+			return previous
+		}
+	}
+}
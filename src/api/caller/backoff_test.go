@@ -0,0 +1,155 @@
+package caller
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+	"github.com/JuanAller/request-builder/src/api/mock"
+)
+
+func delays(strategy BackOffStrategy, retries int) []time.Duration {
+	var result []time.Duration
+	for i := 1; i <= retries; i++ {
+		result = append(result, strategy(i))
+	}
+	return result
+}
+
+func TestBackOffStrategies(t *testing.T) {
+	ms := time.Millisecond
+	cases := []struct {
+		name     string
+		strategy BackOffStrategy
+		expected []time.Duration
+	}{
+		{"constant", ConstantBackOff(10 * ms), []time.Duration{10 * ms, 10 * ms, 10 * ms, 10 * ms, 10 * ms}},
+		{"linear", LinearBackOff(10*ms, 5*ms, 25*ms), []time.Duration{10 * ms, 15 * ms, 20 * ms, 25 * ms, 25 * ms}},
+		{"exponential", ExponentialBackOff(10*ms, 2, 100*ms), []time.Duration{10 * ms, 20 * ms, 40 * ms, 80 * ms, 100 * ms}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if result := delays(c.strategy, 5); !reflect.DeepEqual(result, c.expected) {
+				t.Errorf("Expected %v, but got %v", c.expected, result)
+			}
+		})
+	}
+}
+
+func TestJitterBackOffStrategies(t *testing.T) {
+	initial, max := 10*time.Millisecond, 100*time.Millisecond
+	cases := []struct {
+		name    string
+		factory func(random Random) BackOffStrategy
+		bounds  func(retryNumber int, previous time.Duration) (time.Duration, time.Duration)
+	}{
+		{"full", func(random Random) BackOffStrategy {
+			return FullJitterBackOff(initial, max, random)
+		}, func(retryNumber int, previous time.Duration) (time.Duration, time.Duration) {
+			return 0, ExponentialBackOff(initial, 2, max)(retryNumber)
+		}},
+		{"equal", func(random Random) BackOffStrategy {
+			return EqualJitterBackOff(initial, max, random)
+		}, func(retryNumber int, previous time.Duration) (time.Duration, time.Duration) {
+			exponential := ExponentialBackOff(initial, 2, max)(retryNumber)
+			return exponential / 2, exponential
+		}},
+		{"decorrelated", func(random Random) BackOffStrategy {
+			return DecorrelatedJitterBackOff(initial, max, random)()
+		}, func(retryNumber int, previous time.Duration) (time.Duration, time.Duration) {
+			if previous*3 > max {
+				return initial, max
+			}
+			return initial, previous * 3
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			first := delays(c.factory(rand.New(rand.NewSource(42))), 8)
+			second := delays(c.factory(rand.New(rand.NewSource(42))), 8)
+			if !reflect.DeepEqual(first, second) {
+				t.Errorf("Expected deterministic delays with the same seed, but got %v and %v", first, second)
+			}
+			previous := initial
+			for i, delay := range first {
+				min, max := c.bounds(i+1, previous)
+				if delay < min || delay > max {
+					t.Errorf("Retry %v : expected delay in [%v, %v], but got %v", i+1, min, max, delay)
+				}
+				previous = delay
+			}
+		})
+	}
+}
+
+func TestDecorrelatedJitterBackOff_PerCall(t *testing.T) {
+	executable := &executableMock{
+		mockExecute: func(entityResponse interface{}) *builder.Response {
+			return &builder.Response{StatusCode: http.StatusServiceUnavailable}
+		},
+	}
+	clock := mock.NewClock(time.Now())
+	restCaller := NewRestCaller(executable, nil, func(resp *builder.Response) (error, bool) {
+		return errors.New("an error"), true
+	}, 4, nil).
+		WithBackOffFactory(DecorrelatedJitterBackOff(time.Second, time.Hour, rand.New(rand.NewSource(42)))).
+		WithClock(clock)
+	restCaller.ExecuteCall()
+	restCaller.ExecuteCall()
+
+	sleeps := clock.Sleeps()
+	if len(sleeps) != 8 {
+		t.Fatalf("Expected 8 sleeps, but got %v", sleeps)
+	}
+	for _, first := range []time.Duration{sleeps[0], sleeps[4]} {
+		if first < time.Second || first > 3*time.Second {
+			t.Errorf("Expected the first delay of each call in [1s, 3s], but got %v", first)
+		}
+	}
+}
+
+func TestDecorrelatedJitterBackOff_Concurrent(t *testing.T) {
+	factory := DecorrelatedJitterBackOff(time.Millisecond, time.Second, nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			previous := time.Millisecond
+			for _, delay := range delays(factory(), 10) {
+				if delay < time.Millisecond || delay > 3*previous {
+					t.Errorf("Expected delay in [1ms, %v], but got %v", 3*previous, delay)
+				}
+				previous = delay
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRestCaller_WithClock(t *testing.T) {
+	executable := &executableMock{
+		mockExecute: func(entityResponse interface{}) *builder.Response {
+			return &builder.Response{StatusCode: http.StatusServiceUnavailable}
+		},
+	}
+	clock := mock.NewClock(time.Now())
+	err := NewRestCaller(executable, nil, func(resp *builder.Response) (error, bool) {
+		return errors.New("an error"), true
+	}, 3, ExponentialBackOff(time.Second, 2, time.Minute)).
+		WithClock(clock).
+		ExecuteCall()
+
+	if err == nil {
+		t.Errorf("Expected error")
+	}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	if !reflect.DeepEqual(clock.Sleeps(), expected) {
+		t.Errorf("Expected sleeps %v, but got %v", expected, clock.Sleeps())
+	}
+}
@@ -0,0 +1,110 @@
+package caller
+
+import (
+	"sync"
+	"time"
+)
+
+// RetryBudgetError is returned by restCaller with the error of the last
+// attempt when the retry budget denied the next one.
+type RetryBudgetError struct {
+	Err error
+}
+
+func (e *RetryBudgetError) Error() string {
+	return "retry budget exhausted: " + e.Err.Error()
+}
+
+func (e *RetryBudgetError) Unwrap() error {
+	return e.Err
+}
+
+type RetryBudgetConfig struct {
+	// Ratio of retries to calls allowed in the window, 0.1 by default.
+	Ratio float64
+	// MinPerSecond retries are allowed regardless of the ratio, 10 by default.
+	MinPerSecond float64
+	// Window where calls and retries are counted, 10s by default.
+	Window time.Duration
+	Now    func() time.Time
+}
+
+// RetryBudget is shared by the rest callers of a dependency so that retries
+// stay a fraction of the calls made to it.
+type RetryBudget struct {
+	config  RetryBudgetConfig
+	mutex   sync.Mutex
+	buckets []budgetBucket
+	denied  uint64
+}
+
+type budgetBucket struct {
+	start   time.Time
+	calls   float64
+	retries float64
+}
+
+const budgetBuckets = 10
+
+func NewRetryBudget(config RetryBudgetConfig) *RetryBudget {
+	if config.Ratio <= 0 {
+		config.Ratio = 0.1
+	}
+	if config.MinPerSecond <= 0 {
+		config.MinPerSecond = 10
+	}
+	if config.Window <= 0 {
+		config.Window = 10 * time.Second
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return &RetryBudget{
+		config:  config,
+		buckets: make([]budgetBucket, budgetBuckets),
+	}
+}
+
+// Denied returns the number of retries refused by the budget.
+func (b *RetryBudget) Denied() uint64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.denied
+}
+
+func (b *RetryBudget) call() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.bucket(b.config.Now()).calls++
+}
+
+// withdraw takes a retry from the budget, returning false when it is exhausted.
+func (b *RetryBudget) withdraw() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	now := b.config.Now()
+	current := b.bucket(now)
+	calls, retries := 0.0, 0.0
+	for _, bucket := range b.buckets {
+		if now.Sub(bucket.start) < b.config.Window {
+			calls += bucket.calls
+			retries += bucket.retries
+		}
+	}
+	if retries+1 > b.config.Ratio*calls+b.config.MinPerSecond*b.config.Window.Seconds() {
+		b.denied++
+		return false
+	}
+	current.retries++
+	return true
+}
+
+func (b *RetryBudget) bucket(now time.Time) *budgetBucket {
+	width := b.config.Window / budgetBuckets
+	start := now.Truncate(width)
+	bucket := &b.buckets[int(start.UnixNano()/int64(width))%budgetBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = budgetBucket{start: start}
+	}
+	return bucket
+}
@@ -0,0 +1,68 @@
+package caller
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+)
+
+func TestRetryBudget(t *testing.T) {
+	now := time.Unix(1000, 0)
+	budget := NewRetryBudget(RetryBudgetConfig{Ratio: 0.2, MinPerSecond: 0.1, Window: 10 * time.Second, Now: func() time.Time { return now }})
+
+	if !budget.withdraw() {
+		t.Fatal("Expected the minimum retries to be allowed without calls")
+	}
+	if budget.withdraw() {
+		t.Fatal("Expected the budget to be exhausted")
+	}
+	for i := 0; i < 10; i++ {
+		budget.call()
+	}
+	if !budget.withdraw() || !budget.withdraw() {
+		t.Fatal("Expected 20% of the calls to be retried")
+	}
+	if budget.withdraw() {
+		t.Fatal("Expected the budget to be exhausted")
+	}
+	if denied := budget.Denied(); denied != 2 {
+		t.Errorf("Expected 2 denied retries, but got %v", denied)
+	}
+
+	now = now.Add(10 * time.Second)
+	if !budget.withdraw() {
+		t.Error("Expected the budget to recover once the window slides")
+	}
+}
+
+func TestRestCaller_WithRetryBudget(t *testing.T) {
+	executable := &executableMock{
+		mockExecute: func(entityResponse interface{}) *builder.Response {
+			return &builder.Response{StatusCode: http.StatusServiceUnavailable}
+		},
+	}
+	budget := NewRetryBudget(RetryBudgetConfig{Ratio: 1, MinPerSecond: 0.1})
+	call := func() error {
+		return NewRestCaller(executable, nil, RetryOnServerErrors(), 3, ConstantBackOff(0)).
+			WithRetryBudget(budget).
+			ExecuteCall()
+	}
+
+	call()
+	if executable.totalCalls != 3 {
+		t.Errorf("Expected 2 retries from the budget, but got %v calls", executable.totalCalls)
+	}
+	executable.restartCalls()
+	err := call()
+	var budgetErr *RetryBudgetError
+	var statusErr *StatusError
+	if !errors.As(err, &budgetErr) || !errors.As(err, &statusErr) {
+		t.Errorf("Expected a RetryBudgetError with the last error, but got %v", err)
+	}
+	if executable.totalCalls != 2 {
+		t.Errorf("Expected 1 retry from the budget, but got %v calls", executable.totalCalls)
+	}
+}
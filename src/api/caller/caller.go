@@ -1,6 +1,7 @@
 package caller
 
 import (
+	"context"
 	"github.com/JuanAller/request-builder/src/api/builder"
 	"golang.org/x/sync/errgroup"
 )
@@ -9,6 +10,31 @@ type ExecutableRequest interface {
 	Execute(entityResponse interface{}) *builder.Response
 }
 
+// ContextExecutableRequest is used by restCaller to send each attempt with the call context.
+type ContextExecutableRequest interface {
+	ExecutableRequest
+	ExecuteWithContext(ctx context.Context, entityResponse interface{}) *builder.Response
+}
+
+// CallObserver instruments a call and each of its attempts. The returned
+// functions are called when the call or the attempt ends.
+type CallObserver interface {
+	StartCall(ctx context.Context) (context.Context, func(err error))
+	StartAttempt(ctx context.Context, attempt int) (context.Context, func(resp *builder.Response, err error))
+}
+
+// AttemptGuard is acquired by restCaller before each attempt. An error ends
+// the call without sending the request; release reports the attempt outcome.
+type AttemptGuard interface {
+	Acquire(ctx context.Context) (release func(resp *builder.Response, err error), err error)
+}
+
+// CallGuard is acquired by InParallelCallsLimited before each call. An error
+// skips the call; release reports the error the call ended with.
+type CallGuard interface {
+	AcquireCall(ctx context.Context) (release func(err error), err error)
+}
+
 type Caller interface {
 	ExecuteCall() error
 }
@@ -17,10 +43,29 @@ func InParallelCalls(callers ...Caller) error {
 	var g errgroup.Group
 	for _, caller := range callers {
 		caller := caller
-		g.Go(caller.ExecuteCall())
+		g.Go(caller.ExecuteCall)
 	}
 	if err := g.Wait(); err != nil {
 		return err
 	}
 	return nil
 }
+
+// InParallelCallsLimited acquires guard before each call, so a concurrency
+// limiter decides how many of them run at once.
+func InParallelCallsLimited(ctx context.Context, guard CallGuard, callers ...Caller) error {
+	var g errgroup.Group
+	for _, caller := range callers {
+		caller := caller
+		g.Go(func() error {
+			release, err := guard.AcquireCall(ctx)
+			if err != nil {
+				return err
+			}
+			err = caller.ExecuteCall()
+			release(err)
+			return err
+		})
+	}
+	return g.Wait()
+}
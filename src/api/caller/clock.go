@@ -0,0 +1,30 @@
+package caller
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is used by restCaller to wait between attempts. Tests can replace it
+// with mock.Clock to avoid sleeping.
+type Clock interface {
+	Now() time.Time
+	Sleep(ctx context.Context, duration time.Duration) error
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
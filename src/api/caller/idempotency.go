@@ -0,0 +1,46 @@
+package caller
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+)
+
+type idempotencyKeyHeaderKey struct{}
+
+// WithIdempotencyKey sends a key generated once per ExecuteCall in the
+// Idempotency-Key header of every attempt, so IdempotentOnly retries the call
+// whatever its method. It needs a ContextExecutableRequest.
+func (c *restCaller) WithIdempotencyKey() *restCaller {
+	return c.WithIdempotencyKeyHeader(DefaultIdempotencyKeyHeader)
+}
+
+// WithIdempotencyKeyHeader is WithIdempotencyKey sending the key in header.
+func (c *restCaller) WithIdempotencyKeyHeader(header string) *restCaller {
+	c.keyHeader = header
+	return c
+}
+
+// NewIdempotencyKey returns a random UUID.
+func NewIdempotencyKey() string {
+	var uuid [16]byte
+	rand.Read(uuid[:])
+	uuid[6] = uuid[6]&0x0f | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:])
+}
+
+func withIdempotencyKey(ctx context.Context, header string, key string) context.Context {
+	return builder.ContextWithHeader(context.WithValue(ctx, idempotencyKeyHeaderKey{}, header), header, key)
+}
+
+func hasIdempotencyKey(request *http.Request) bool {
+	header, ok := request.Context().Value(idempotencyKeyHeaderKey{}).(string)
+	if !ok {
+		header = DefaultIdempotencyKeyHeader
+	}
+	return request.Header.Get(header) != ""
+}
@@ -0,0 +1,62 @@
+package caller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+)
+
+func TestRestCaller_WithIdempotencyKey(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("X-Request-Key"))
+		if len(keys)%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", builder.APPLICATIONJSON)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	call := func() error {
+		entity := make(map[string]string)
+		request := builder.Post(http.DefaultClient, server.URL).WithBody(map[string]string{"name": "aName"})
+		return NewRestCaller(request, &entity, DefaultRetryPolicy(), 2, ConstantBackOff(0)).
+			WithIdempotencyKeyHeader("X-Request-Key").
+			ExecuteCall()
+	}
+	if err := call(); err != nil {
+		t.Fatalf("Expected the keyed POST to be retried, but got %v", err)
+	}
+	if err := call(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keys) != 6 || keys[0] == "" {
+		t.Fatalf("Expected 6 keyed attempts, but got %v", keys)
+	}
+	if keys[0] != keys[1] || keys[1] != keys[2] {
+		t.Errorf("Expected the same key for every retry, but got %v", keys[:3])
+	}
+	if keys[3] == keys[0] || keys[3] != keys[5] {
+		t.Errorf("Expected a new key for each call, but got %v", keys)
+	}
+}
+
+func TestRestCaller_WithoutIdempotencyKey(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	request := builder.Post(http.DefaultClient, server.URL).WithBody(map[string]string{})
+	NewRestCaller(request, nil, DefaultRetryPolicy(), 2, ConstantBackOff(0)).ExecuteCall()
+	if calls != 1 {
+		t.Errorf("Expected no retry of a POST without key, but got %v calls", calls)
+	}
+}
@@ -0,0 +1,192 @@
+package caller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+)
+
+const DefaultIdempotencyKeyHeader = "Idempotency-Key"
+
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d", e.StatusCode)
+}
+
+// DefaultMaxRetryAfter is the longest Retry-After a restCaller waits for,
+// unless changed with WithMaxRetryAfter.
+const DefaultMaxRetryAfter = time.Minute
+
+// RetryAfterError asks restCaller to wait for the Retry-After header of the
+// response before the next attempt instead of the delay of its
+// BackOffStrategy. The delay is taken from the Clock of the restCaller.
+type RetryAfterError struct {
+	Err error
+	// RetryAfter is the value of the header, in seconds or as an HTTP date.
+	RetryAfter string
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryOnNetworkErrors retries requests that got no response, including
+// timeouts, but not requests that were not sent because of a ConfigError.
+func RetryOnNetworkErrors() ResponseHandler {
+	return func(resp *builder.Response) (error, bool) {
+		var configErr *builder.ConfigError
+		if errors.As(resp.Error, &configErr) {
+			return resp.Error, false
+		}
+		if resp.Error != nil && resp.StatusCode == 0 {
+			return resp.Error, true
+		}
+		return nil, false
+	}
+}
+
+func RetryOnStatus(statusCodes ...int) ResponseHandler {
+	retryable := make(map[int]bool, len(statusCodes))
+	for _, statusCode := range statusCodes {
+		retryable[statusCode] = true
+	}
+	return func(resp *builder.Response) (error, bool) {
+		if retryable[resp.StatusCode] {
+			return &StatusError{StatusCode: resp.StatusCode}, true
+		}
+		return nil, false
+	}
+}
+
+// RetryOnServerErrors retries 429 and every 5xx response but 501, which a
+// retry would not change.
+func RetryOnServerErrors() ResponseHandler {
+	return func(resp *builder.Response) (error, bool) {
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented {
+			return &StatusError{StatusCode: resp.StatusCode}, true
+		}
+		return nil, false
+	}
+}
+
+// FailOnError fails without retry on any error or status code from 400 up.
+func FailOnError() ResponseHandler {
+	return func(resp *builder.Response) (error, bool) {
+		if resp.Error != nil {
+			return resp.Error, false
+		}
+		if resp.StatusCode >= 400 {
+			return &StatusError{StatusCode: resp.StatusCode}, false
+		}
+		return nil, false
+	}
+}
+
+// Compose returns the result of the first handler that reports an error.
+func Compose(handlers ...ResponseHandler) ResponseHandler {
+	return func(resp *builder.Response) (error, bool) {
+		for _, handler := range handlers {
+			if err, retry := handler(resp); err != nil {
+				return err, retry
+			}
+		}
+		return nil, false
+	}
+}
+
+// HonourRetryAfter makes retries of handler wait for the Retry-After header
+// of the response, in seconds or as an HTTP date, when present. Calls give up
+// instead when it asks for more than the maximum of the restCaller.
+func HonourRetryAfter(handler ResponseHandler) ResponseHandler {
+	return func(resp *builder.Response) (error, bool) {
+		err, retry := handler(resp)
+		if err == nil || !retry {
+			return err, retry
+		}
+		if value := strings.TrimSpace(resp.Header.Get("Retry-After")); value != "" {
+			return &RetryAfterError{Err: err, RetryAfter: value}, true
+		}
+		return err, retry
+	}
+}
+
+// IdempotentOnly only lets handler retry idempotent methods, or any method
+// sent with an Idempotency-Key header or the header of WithIdempotencyKeyHeader.
+// Responses without a request, as the ones of an ExecutableRequest other than
+// a builder, have no method to check, so handler decides alone.
+func IdempotentOnly(handler ResponseHandler) ResponseHandler {
+	return func(resp *builder.Response) (error, bool) {
+		err, retry := handler(resp)
+		if !retry {
+			return err, false
+		}
+		if resp.Request == nil {
+			return err, true
+		}
+		return err, idempotentMethods[resp.Request.Method] || hasIdempotencyKey(resp.Request)
+	}
+}
+
+// DefaultRetryPolicy retries network errors, 429 and 5xx on idempotent
+// requests honouring Retry-After, composed after the given handlers.
+func DefaultRetryPolicy(handlers ...ResponseHandler) ResponseHandler {
+	return IdempotentOnly(HonourRetryAfter(Compose(append(handlers,
+		RetryOnNetworkErrors(),
+		RetryOnServerErrors(),
+		FailOnError())...)))
+}
+
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if delay := date.Sub(now); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
+
+// retryDelay returns the Retry-After of err, or backOff when it has none. It
+// returns false when Retry-After is longer than maxDelay.
+func retryDelay(err error, backOff time.Duration, now time.Time, maxDelay time.Duration) (time.Duration, bool) {
+	var retryAfterErr *RetryAfterError
+	if !errors.As(err, &retryAfterErr) {
+		return backOff, true
+	}
+	delay, ok := retryAfter(retryAfterErr.RetryAfter, now)
+	if !ok {
+		return backOff, true
+	}
+	return delay, delay <= maxDelay
+}
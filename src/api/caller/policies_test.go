@@ -0,0 +1,137 @@
+package caller
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+	"github.com/JuanAller/request-builder/src/api/mock"
+)
+
+func newResponse(method string, statusCode int, header http.Header) *builder.Response {
+	request, _ := http.NewRequest(method, "http://test/policies", nil)
+	if header == nil {
+		header = http.Header{}
+	}
+	return &builder.Response{
+		StatusCode: statusCode,
+		Header:     header,
+		Request:    request,
+	}
+}
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	keyed := newResponse(http.MethodPost, http.StatusServiceUnavailable, nil)
+	keyed.Request.Header.Set("Idempotency-Key", "a-key")
+	networkError := newResponse(http.MethodGet, 0, nil)
+	networkError.Error = errors.New("connection refused")
+	configError := &builder.Response{Error: errors.Join(&builder.ConfigError{Err: errors.New("invalid header name")})}
+	notBuilt := builder.Post(&mock.HttpClientMock{}, "http://test/policies").WithBody(func() {}).Execute(nil)
+	withoutRequest := &builder.Response{StatusCode: http.StatusServiceUnavailable}
+	notFoundHandler := func(resp *builder.Response) (error, bool) {
+		if resp.StatusCode == http.StatusNotFound {
+			return errors.New("not found"), true
+		}
+		return nil, false
+	}
+
+	cases := []struct {
+		name          string
+		resp          *builder.Response
+		expectedError bool
+		expectedRetry bool
+	}{
+		{name: "ok", resp: newResponse(http.MethodGet, http.StatusOK, nil)},
+		{name: "bad_request", resp: newResponse(http.MethodGet, http.StatusBadRequest, nil), expectedError: true},
+		{name: "not_found_user_handler", resp: newResponse(http.MethodGet, http.StatusNotFound, nil), expectedError: true, expectedRetry: true},
+		{name: "too_many_requests", resp: newResponse(http.MethodGet, http.StatusTooManyRequests, nil), expectedError: true, expectedRetry: true},
+		{name: "server_error_get", resp: newResponse(http.MethodGet, http.StatusBadGateway, nil), expectedError: true, expectedRetry: true},
+		{name: "server_error_post", resp: newResponse(http.MethodPost, http.StatusBadGateway, nil), expectedError: true},
+		{name: "server_error_keyed_post", resp: keyed, expectedError: true, expectedRetry: true},
+		{name: "network_error", resp: networkError, expectedError: true, expectedRetry: true},
+		{name: "config_error", resp: configError, expectedError: true},
+		{name: "request_not_built", resp: notBuilt, expectedError: true},
+		{name: "response_without_request", resp: withoutRequest, expectedError: true, expectedRetry: true},
+		{name: "not_implemented", resp: newResponse(http.MethodGet, http.StatusNotImplemented, nil), expectedError: true},
+	}
+
+	policy := DefaultRetryPolicy(notFoundHandler)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err, retry := policy(c.resp)
+			if (err != nil) != c.expectedError || retry != c.expectedRetry {
+				t.Errorf("Expected error %v and retry %v, but got %v %v", c.expectedError, c.expectedRetry, err, retry)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+	cases := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{value: "120", expected: 2 * time.Minute, ok: true},
+		{value: "Wed, 21 Oct 2015 07:28:30 GMT", expected: 30 * time.Second, ok: true},
+		{value: "Wed, 21 Oct 2015 07:27:00 GMT", expected: 0, ok: true},
+		{value: "soon"},
+		{value: ""},
+	}
+	for _, c := range cases {
+		delay, ok := retryAfter(c.value, now)
+		if delay != c.expected || ok != c.ok {
+			t.Errorf("Retry-After %q : expected %v %v, but got %v %v", c.value, c.expected, c.ok, delay, ok)
+		}
+	}
+}
+
+func TestRestCaller_HonoursRetryAfter(t *testing.T) {
+	executable := &executableMock{
+		mockExecute: func(entityResponse interface{}) *builder.Response {
+			return newResponse(http.MethodGet, http.StatusTooManyRequests, http.Header{"Retry-After": {"0"}})
+		},
+	}
+	start := time.Now()
+	err := NewRestCaller(executable, nil, DefaultRetryPolicy(), 2, func(retry int) time.Duration {
+		return time.Hour
+	}).ExecuteCall()
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected StatusError 429, but got %v", err)
+	}
+	if executable.totalCalls != 3 || time.Since(start) > time.Second {
+		t.Errorf("Expected 3 calls without backing off, but got %v in %v", executable.totalCalls, time.Since(start))
+	}
+}
+
+func TestRestCaller_RetryAfterWithClock(t *testing.T) {
+	clock := mock.NewClock(time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC))
+	retryAfter := "Wed, 21 Oct 2015 07:28:30 GMT"
+	executable := &executableMock{
+		mockExecute: func(entityResponse interface{}) *builder.Response {
+			return newResponse(http.MethodGet, http.StatusServiceUnavailable, http.Header{"Retry-After": {retryAfter}})
+		},
+	}
+	NewRestCaller(executable, nil, DefaultRetryPolicy(), 1, ConstantBackOff(time.Hour)).
+		WithClock(clock).
+		ExecuteCall()
+	if sleeps := clock.Sleeps(); len(sleeps) != 1 || sleeps[0] != 30*time.Second {
+		t.Errorf("Expected to wait until the Retry-After date of the clock, but got %v", sleeps)
+	}
+
+	retryAfter = "3600"
+	executable.restartCalls()
+	err := NewRestCaller(executable, nil, DefaultRetryPolicy(), 1, ConstantBackOff(0)).
+		WithClock(clock).
+		WithMaxRetryAfter(time.Minute).
+		ExecuteCall()
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || executable.totalCalls != 1 || len(clock.Sleeps()) != 1 {
+		t.Errorf("Expected to give up on a Retry-After over the maximum, but got %v after %v calls", err, executable.totalCalls)
+	}
+}
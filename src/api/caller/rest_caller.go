@@ -1,6 +1,8 @@
 package caller
 
 import (
+	"context"
+	"errors"
 	"time"
 	"github.com/JuanAller/request-builder/src/api/builder"
 )
@@ -8,12 +10,31 @@ import (
 type ResponseHandler func(resp *builder.Response) (err error, retry bool)
 type BackOffStrategy func(retryNumber int) time.Duration
 
+// BackOffFactory creates the BackOffStrategy of each call, for strategies
+// whose delays depend on the previous ones of the call.
+type BackOffFactory func() BackOffStrategy
+type RetryHook func(retryNumber int, resp *builder.Response, err error)
+
+// ErrContextUnsupported ends the calls with timeouts whose request is not a
+// ContextExecutableRequest, as they could not be bounded.
+var ErrContextUnsupported = errors.New("caller: timeouts need a ContextExecutableRequest")
+
 type restCaller struct {
 	requestBuilder  ExecutableRequest
 	Entity          interface{}
 	responseHandler ResponseHandler
 	retries         int
-	backOff         BackOffStrategy
+	newBackOff      BackOffFactory
+	retryHooks      []RetryHook
+	observers       []CallObserver
+	ctx             context.Context
+	attemptTimeout  time.Duration
+	timeout         time.Duration
+	clock           Clock
+	guards          []AttemptGuard
+	retryBudget     *RetryBudget
+	keyHeader       string
+	maxRetryAfter   time.Duration
 }
 
 func NewRestCaller(r ExecutableRequest, entity interface{}, rh ResponseHandler, retries int, bos BackOffStrategy) *restCaller {
@@ -22,12 +43,106 @@ func NewRestCaller(r ExecutableRequest, entity interface{}, rh ResponseHandler,
 		Entity:          entity,
 		responseHandler: rh,
 		retries:         retries,
-		backOff:         bos,
+		newBackOff:      func() BackOffStrategy { return bos },
+		ctx:             context.Background(),
+		clock:           realClock{},
+		maxRetryAfter:   DefaultMaxRetryAfter,
 	}
 }
 
+// WithBackOffFactory replaces the BackOffStrategy of the caller with one
+// created by factory for each call.
+func (c *restCaller) WithBackOffFactory(factory BackOffFactory) *restCaller {
+	c.newBackOff = factory
+	return c
+}
+
+func (c *restCaller) WithClock(clock Clock) *restCaller {
+	c.clock = clock
+	return c
+}
+
+// WithMaxRetryAfter sets the longest Retry-After the call waits for. Longer
+// ones end the call with the error of the response.
+func (c *restCaller) WithMaxRetryAfter(maxDelay time.Duration) *restCaller {
+	c.maxRetryAfter = maxDelay
+	return c
+}
+
+// WithContext sets the context every attempt is executed with, when the request supports it.
+func (c *restCaller) WithContext(ctx context.Context) *restCaller {
+	c.ctx = ctx
+	return c
+}
+
+// WithAttemptTimeout bounds each attempt. Attempts that time out get a
+// *builder.TimeoutError, which the ResponseHandler can decide to retry. The
+// request must be a ContextExecutableRequest, or the call fails with
+// ErrContextUnsupported.
+func (c *restCaller) WithAttemptTimeout(timeout time.Duration) *restCaller {
+	c.attemptTimeout = timeout
+	return c
+}
+
+// WithTimeout bounds the whole call, including every retry and backoff, with
+// the same requirement as WithAttemptTimeout.
+func (c *restCaller) WithTimeout(timeout time.Duration) *restCaller {
+	c.timeout = timeout
+	return c
+}
+
+// Guard adds a guard acquired before each attempt, in the order they were added.
+func (c *restCaller) Guard(guard AttemptGuard) *restCaller {
+	c.guards = append(c.guards, guard)
+	return c
+}
+
+// WithRetryBudget counts the call in budget and asks it before each retry.
+func (c *restCaller) WithRetryBudget(budget *RetryBudget) *restCaller {
+	c.retryBudget = budget
+	return c
+}
+
+func (c *restCaller) Observe(observer CallObserver) *restCaller {
+	c.observers = append(c.observers, observer)
+	return c
+}
+
+// OnRetry registers a hook called before each retry with the response that caused it.
+func (c *restCaller) OnRetry(hook RetryHook) *restCaller {
+	c.retryHooks = append(c.retryHooks, hook)
+	return c
+}
+
 func (c *restCaller) ExecuteCall() error {
-	err, retry := c.responseHandler(c.requestBuilder.Execute(c.Entity))
+	if _, ok := c.requestBuilder.(ContextExecutableRequest); !ok && (c.timeout > 0 || c.attemptTimeout > 0) {
+		return ErrContextUnsupported
+	}
+	ctx := c.ctx
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	if c.keyHeader != "" {
+		ctx = withIdempotencyKey(ctx, c.keyHeader, NewIdempotencyKey())
+	}
+	ends := make([]func(err error), len(c.observers))
+	for i, observer := range c.observers {
+		ctx, ends[i] = observer.StartCall(ctx)
+	}
+	err := c.executeAttempts(ctx, c.newBackOff())
+	for i := len(ends) - 1; i >= 0; i-- {
+		ends[i](err)
+	}
+	return err
+}
+
+func (c *restCaller) executeAttempts(ctx context.Context, backOff BackOffStrategy) error {
+	if c.retryBudget != nil {
+		c.retryBudget.call()
+	}
+	resp, err, retry := c.guardedAttempt(ctx, 0)
 	for i := 1; i <= c.retries; i++ {
 		if err == nil {
 			return nil
@@ -35,8 +150,69 @@ func (c *restCaller) ExecuteCall() error {
 		if !retry {
 			return err
 		}
-		time.Sleep(c.backOff(i))
-		err, retry = c.responseHandler(c.requestBuilder.Execute(c.Entity))
+		if ctx.Err() != nil {
+			return err
+		}
+		delay, ok := retryDelay(err, backOff(i), c.clock.Now(), c.maxRetryAfter)
+		if !ok {
+			return err
+		}
+		if c.retryBudget != nil && !c.retryBudget.withdraw() {
+			return &RetryBudgetError{Err: err}
+		}
+		for _, hook := range c.retryHooks {
+			hook(i, resp, err)
+		}
+		if sleepErr := c.clock.Sleep(ctx, delay); errors.Is(sleepErr, context.DeadlineExceeded) {
+			return &builder.TimeoutError{Err: sleepErr}
+		} else if sleepErr != nil {
+			return sleepErr
+		}
+		resp, err, retry = c.guardedAttempt(ctx, i)
 	}
 	return err
 }
+
+// guardedAttempt fails without retry when a guard rejects the attempt. Guards
+// already acquired are released with a nil response, as nothing was sent.
+func (c *restCaller) guardedAttempt(ctx context.Context, attempt int) (*builder.Response, error, bool) {
+	releases := make([]func(resp *builder.Response, err error), 0, len(c.guards))
+	for _, guard := range c.guards {
+		release, err := guard.Acquire(ctx)
+		if err != nil {
+			for i := len(releases) - 1; i >= 0; i-- {
+				releases[i](nil, err)
+			}
+			return nil, err, false
+		}
+		releases = append(releases, release)
+	}
+	resp, err, retry := c.attempt(ctx, attempt)
+	for i := len(releases) - 1; i >= 0; i-- {
+		releases[i](resp, err)
+	}
+	return resp, err, retry
+}
+
+func (c *restCaller) attempt(ctx context.Context, attempt int) (*builder.Response, error, bool) {
+	if c.attemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.attemptTimeout)
+		defer cancel()
+	}
+	ends := make([]func(resp *builder.Response, err error), len(c.observers))
+	for i, observer := range c.observers {
+		ctx, ends[i] = observer.StartAttempt(ctx, attempt)
+	}
+	var resp *builder.Response
+	if executable, ok := c.requestBuilder.(ContextExecutableRequest); ok {
+		resp = executable.ExecuteWithContext(ctx, c.Entity)
+	} else {
+		resp = c.requestBuilder.Execute(c.Entity)
+	}
+	err, retry := c.responseHandler(resp)
+	for i := len(ends) - 1; i >= 0; i-- {
+		ends[i](resp, err)
+	}
+	return resp, err, retry
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"github.com/JuanAller/request-builder/src/api/builder"
 	"net/http"
+	"net/http/httptest"
 	"errors"
 	"time"
 	"fmt"
@@ -133,3 +134,104 @@ func TestRestCaller_ExecuteCall(t *testing.T) {
 		})
 	}
 }
+
+func TestRestCaller_OnRetry(t *testing.T) {
+	executable := &executableMock{
+		mockExecute: func(entityResponse interface{}) *builder.Response {
+			return &builder.Response{
+				StatusCode: http.StatusServiceUnavailable,
+			}
+		},
+	}
+	var retries []int
+	err := NewRestCaller(executable, nil, func(resp *builder.Response) (error, bool) {
+		return errors.New("an error"), true
+	}, 2, func(retry int) time.Duration {
+		return 0
+	}).OnRetry(func(retryNumber int, resp *builder.Response, err error) {
+		if resp.StatusCode != http.StatusServiceUnavailable || err.Error() != "an error" {
+			t.Errorf("Unexpected retry cause %v %v", resp.StatusCode, err)
+		}
+		retries = append(retries, retryNumber)
+	}).ExecuteCall()
+
+	if err == nil {
+		t.Errorf("Expected error")
+	}
+	if len(retries) != 2 || retries[0] != 1 || retries[1] != 2 {
+		t.Errorf("Expected retries 1 and 2, but got %v", retries)
+	}
+}
+
+func TestRestCaller_Timeouts(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+	timeoutHandler := func(resp *builder.Response) (error, bool) {
+		if resp.Error != nil {
+			return resp.Error, builder.IsTimeout(resp.Error)
+		}
+		return nil, false
+	}
+	noBackOff := func(retry int) time.Duration {
+		return 0
+	}
+
+	cases := []struct {
+		name           string
+		attemptTimeout time.Duration
+		timeout        time.Duration
+		backOff        BackOffStrategy
+		maxElapsed     time.Duration
+	}{
+		{name: "attempt_timeout", attemptTimeout: 20 * time.Millisecond, backOff: noBackOff, maxElapsed: 500 * time.Millisecond},
+		{name: "overall_timeout", timeout: 50 * time.Millisecond, backOff: noBackOff, maxElapsed: 500 * time.Millisecond},
+		{name: "timeout_during_backoff", attemptTimeout: 10 * time.Millisecond, timeout: 50 * time.Millisecond,
+			backOff: func(retry int) time.Duration {
+				return time.Hour
+			}, maxElapsed: 500 * time.Millisecond},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			start := time.Now()
+			err := NewRestCaller(builder.Get(http.DefaultClient, server.URL), nil, timeoutHandler, 2, c.backOff).
+				WithAttemptTimeout(c.attemptTimeout).
+				WithTimeout(c.timeout).
+				ExecuteCall()
+
+			var timeoutErr *builder.TimeoutError
+			if !errors.As(err, &timeoutErr) {
+				t.Errorf("Expected TimeoutError, but got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > c.maxElapsed {
+				t.Errorf("Expected call to end before %v, but took %v", c.maxElapsed, elapsed)
+			}
+		})
+	}
+}
+
+func TestRestCaller_TimeoutsNeedContext(t *testing.T) {
+	executable := &executableMock{
+		mockExecute: func(entityResponse interface{}) *builder.Response {
+			return &builder.Response{StatusCode: http.StatusOK}
+		},
+	}
+	noBackOff := func(retry int) time.Duration {
+		return 0
+	}
+	for _, restCaller := range []*restCaller{
+		NewRestCaller(executable, nil, DefaultRetryPolicy(), 0, noBackOff).WithTimeout(time.Second),
+		NewRestCaller(executable, nil, DefaultRetryPolicy(), 0, noBackOff).WithAttemptTimeout(time.Second),
+	} {
+		if err := restCaller.ExecuteCall(); err != ErrContextUnsupported {
+			t.Errorf("Expected ErrContextUnsupported, but got %v", err)
+		}
+	}
+	if executable.totalCalls != 0 {
+		t.Errorf("Expected no request to be sent, but got %v", executable.totalCalls)
+	}
+}
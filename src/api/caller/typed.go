@@ -0,0 +1,97 @@
+package caller
+
+import (
+	"context"
+	"time"
+)
+
+// typedRestCaller decodes each call into a new T. It is configured as the
+// restCaller it embeds, through methods that return the typedRestCaller so
+// they chain into Call.
+type typedRestCaller[T any] struct {
+	*restCaller
+	result T
+}
+
+func NewTypedRestCaller[T any](r ExecutableRequest, rh ResponseHandler, retries int, bos BackOffStrategy) *typedRestCaller[T] {
+	c := &typedRestCaller[T]{restCaller: NewRestCaller(r, nil, rh, retries, bos)}
+	c.restCaller.Entity = &c.result
+	return c
+}
+
+func (c *typedRestCaller[T]) ExecuteCall() error {
+	var zero T
+	c.result = zero
+	return c.restCaller.ExecuteCall()
+}
+
+// Call executes the call and returns the value decoded by its last attempt.
+func (c *typedRestCaller[T]) Call() (T, error) {
+	err := c.ExecuteCall()
+	return c.result, err
+}
+
+// Result returns the value decoded by the last call, for callers run with InParallelCalls.
+func (c *typedRestCaller[T]) Result() T {
+	return c.result
+}
+
+func (c *typedRestCaller[T]) WithBackOffFactory(factory BackOffFactory) *typedRestCaller[T] {
+	c.restCaller.WithBackOffFactory(factory)
+	return c
+}
+
+func (c *typedRestCaller[T]) WithClock(clock Clock) *typedRestCaller[T] {
+	c.restCaller.WithClock(clock)
+	return c
+}
+
+func (c *typedRestCaller[T]) WithMaxRetryAfter(maxDelay time.Duration) *typedRestCaller[T] {
+	c.restCaller.WithMaxRetryAfter(maxDelay)
+	return c
+}
+
+func (c *typedRestCaller[T]) WithContext(ctx context.Context) *typedRestCaller[T] {
+	c.restCaller.WithContext(ctx)
+	return c
+}
+
+func (c *typedRestCaller[T]) WithAttemptTimeout(timeout time.Duration) *typedRestCaller[T] {
+	c.restCaller.WithAttemptTimeout(timeout)
+	return c
+}
+
+func (c *typedRestCaller[T]) WithTimeout(timeout time.Duration) *typedRestCaller[T] {
+	c.restCaller.WithTimeout(timeout)
+	return c
+}
+
+func (c *typedRestCaller[T]) Guard(guard AttemptGuard) *typedRestCaller[T] {
+	c.restCaller.Guard(guard)
+	return c
+}
+
+func (c *typedRestCaller[T]) WithRetryBudget(budget *RetryBudget) *typedRestCaller[T] {
+	c.restCaller.WithRetryBudget(budget)
+	return c
+}
+
+func (c *typedRestCaller[T]) Observe(observer CallObserver) *typedRestCaller[T] {
+	c.restCaller.Observe(observer)
+	return c
+}
+
+func (c *typedRestCaller[T]) OnRetry(hook RetryHook) *typedRestCaller[T] {
+	c.restCaller.OnRetry(hook)
+	return c
+}
+
+func (c *typedRestCaller[T]) WithIdempotencyKey() *typedRestCaller[T] {
+	c.restCaller.WithIdempotencyKey()
+	return c
+}
+
+func (c *typedRestCaller[T]) WithIdempotencyKeyHeader(header string) *typedRestCaller[T] {
+	c.restCaller.WithIdempotencyKeyHeader(header)
+	return c
+}
@@ -0,0 +1,53 @@
+package caller
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+	"github.com/JuanAller/request-builder/src/api/mock"
+)
+
+type typedItem struct {
+	ID string `json:"id"`
+}
+
+func TestTypedRestCaller(t *testing.T) {
+	calls := 0
+	client := &mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				return mock.NewJsonResponse(http.StatusServiceUnavailable, map[string]string{})
+			}
+			return mock.NewJsonResponse(http.StatusOK, map[string]string{"id": request.URL.Path})
+		},
+	}
+
+	retries := 0
+	first := NewTypedRestCaller[typedItem](builder.Get(client, "http://test/1"), DefaultRetryPolicy(), 1, ConstantBackOff(0))
+	item, err := first.
+		WithTimeout(time.Second).
+		OnRetry(func(retryNumber int, resp *builder.Response, err error) { retries++ }).
+		Call()
+	if err != nil || item.ID != "/1" {
+		t.Errorf("Unexpected result %v %v", item, err)
+	}
+	if retries != 1 {
+		t.Errorf("Expected 1 retry, but got %v", retries)
+	}
+
+	calls = 1
+	second := NewTypedRestCaller[typedItem](builder.Get(&mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			return mock.NewJsonResponse(http.StatusOK, map[string]string{"id": request.URL.Path})
+		},
+	}, "http://test/2"), DefaultRetryPolicy(), 0, ConstantBackOff(0))
+	if err := InParallelCalls(first, second); err != nil {
+		t.Fatal(err)
+	}
+	if first.Result().ID != "/1" || second.Result().ID != "/2" {
+		t.Errorf("Unexpected results %v %v", first.Result(), second.Result())
+	}
+}
@@ -0,0 +1,57 @@
+package dependency
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Registry creates a value per host or named dependency the first time it is
+// asked for, so every request to the dependency shares it.
+type Registry[T any] struct {
+	newValue func(name string) T
+	mutex    sync.Mutex
+	values   map[string]T
+}
+
+func NewRegistry[T any](newValue func(name string) T) *Registry[T] {
+	return &Registry[T]{
+		newValue: newValue,
+		values:   make(map[string]T),
+	}
+}
+
+func (r *Registry[T]) Get(name string) T {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	value, ok := r.values[name]
+	if !ok {
+		value = r.newValue(name)
+		r.values[name] = value
+	}
+	return value
+}
+
+// All returns the values created so far by name.
+func (r *Registry[T]) All() map[string]T {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	values := make(map[string]T, len(r.values))
+	for name, value := range r.values {
+		values[name] = value
+	}
+	return values
+}
+
+// KeyFunc names the dependency of a request.
+type KeyFunc func(request *http.Request) string
+
+func ByHost(request *http.Request) string {
+	return request.URL.Host
+}
+
+// Named keys every request to the same dependency.
+func Named(name string) KeyFunc {
+	return func(request *http.Request) string {
+		return name
+	}
+}
@@ -0,0 +1,32 @@
+package dependency
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	created := 0
+	registry := NewRegistry(func(name string) *string {
+		created++
+		return &name
+	})
+	users := registry.Get("users")
+	if registry.Get("users") != users || *users != "users" {
+		t.Errorf("Expected the same value for the same name")
+	}
+	registry.Get("orders")
+	if all := registry.All(); created != 2 || len(all) != 2 || all["users"] != users {
+		t.Errorf("Expected a value per name, but got %v after %v created", all, created)
+	}
+}
+
+func TestKeyFuncs(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodGet, "http://users.test:8080/users", nil)
+	if key := ByHost(request); key != "users.test:8080" {
+		t.Errorf("Expected the host, but got %v", key)
+	}
+	if key := Named("users")(request); key != "users" {
+		t.Errorf("Expected the name, but got %v", key)
+	}
+}
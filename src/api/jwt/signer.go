@@ -0,0 +1,225 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	RS256 = "RS256"
+	ES256 = "ES256"
+	HS256 = "HS256"
+
+	defaultTTL         = 5 * time.Minute
+	defaultExpiryDelta = 30 * time.Second
+)
+
+type Config struct {
+	Algorithm string
+	// Key is a []byte secret for HS256, an *rsa.PrivateKey for RS256 and an
+	// *ecdsa.PrivateKey on the P-256 curve for ES256.
+	Key      interface{}
+	KeyID    string
+	Issuer   string
+	Subject  string
+	Audience string
+	TTL      time.Duration
+	// ExpiryDelta is how long before its expiry a cached token is replaced.
+	ExpiryDelta time.Duration
+	Claims      map[string]interface{}
+}
+
+type signer struct {
+	config Config
+	mutex  sync.Mutex
+	token  string
+	expiry time.Time
+	now    func() time.Time
+}
+
+func NewSigner(config Config) (*signer, error) {
+	if err := checkKey(config.Algorithm, config.Key); err != nil {
+		return nil, err
+	}
+	if config.TTL == 0 {
+		config.TTL = defaultTTL
+	}
+	if config.ExpiryDelta == 0 {
+		config.ExpiryDelta = defaultExpiryDelta
+	}
+	return &signer{
+		config: config,
+		now:    time.Now,
+	}, nil
+}
+
+func (s *signer) Token() (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := s.now()
+	if s.token != "" && now.Add(s.config.ExpiryDelta).Before(s.expiry) {
+		return s.token, nil
+	}
+	token, expiry, err := s.mint(now)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.expiry = token, expiry
+	return token, nil
+}
+
+func (s *signer) Authorize(request *http.Request) error {
+	token, err := s.Token()
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (s *signer) mint(now time.Time) (string, time.Time, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", time.Time{}, err
+	}
+	expiry := now.Add(s.config.TTL)
+	claims := map[string]interface{}{}
+	for key, value := range s.config.Claims {
+		claims[key] = value
+	}
+	setClaim(claims, "iss", s.config.Issuer)
+	setClaim(claims, "sub", s.config.Subject)
+	setClaim(claims, "aud", s.config.Audience)
+	claims["iat"] = now.Unix()
+	claims["exp"] = expiry.Unix()
+	claims["jti"] = hex.EncodeToString(jti)
+
+	header := map[string]string{"alg": s.config.Algorithm, "typ": "JWT"}
+	if s.config.KeyID != "" {
+		header["kid"] = s.config.KeyID
+	}
+	encodedHeader, err := encodeSegment(header)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	encodedClaims, err := encodeSegment(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	signingInput := encodedHeader + "." + encodedClaims
+	signature, err := sign(s.config.Algorithm, s.config.Key, []byte(signingInput))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), expiry, nil
+}
+
+func setClaim(claims map[string]interface{}, key string, value string) {
+	if value != "" {
+		claims[key] = value
+	}
+}
+
+func encodeSegment(v interface{}) (string, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+func checkKey(algorithm string, key interface{}) error {
+	var ok bool
+	switch algorithm {
+	case HS256:
+		var secret []byte
+		secret, ok = key.([]byte)
+		ok = ok && len(secret) > 0
+	case RS256:
+		var rsaKey *rsa.PrivateKey
+		rsaKey, ok = key.(*rsa.PrivateKey)
+		ok = ok && rsaKey != nil
+	case ES256:
+		var ecKey *ecdsa.PrivateKey
+		ecKey, ok = key.(*ecdsa.PrivateKey)
+		ok = ok && ecKey != nil && ecKey.Curve != nil && ecKey.Curve.Params().BitSize == 256
+	default:
+		return fmt.Errorf("jwt: unsupported algorithm %q", algorithm)
+	}
+	if !ok {
+		return fmt.Errorf("jwt: invalid key %T for algorithm %s", key, algorithm)
+	}
+	return nil
+}
+
+func sign(algorithm string, key interface{}, signingInput []byte) ([]byte, error) {
+	digest := sha256.Sum256(signingInput)
+	switch algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write(signingInput)
+		return mac.Sum(nil), nil
+	case RS256:
+		return rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, digest[:])
+	case ES256:
+		r, s, err := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), digest[:])
+		if err != nil {
+			return nil, err
+		}
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return signature, nil
+	}
+	return nil, errors.New("jwt: unsupported algorithm " + algorithm)
+}
+
+// Verify checks the signature of token with the public counterpart of the
+// signing key and returns its claims. Expiry is not validated.
+func Verify(token string, algorithm string, key interface{}) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("jwt: malformed token")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	signingInput := []byte(parts[0] + "." + parts[1])
+	digest := sha256.Sum256(signingInput)
+	valid := false
+	switch publicKey := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, publicKey)
+		mac.Write(signingInput)
+		valid = algorithm == HS256 && hmac.Equal(signature, mac.Sum(nil))
+	case *rsa.PublicKey:
+		valid = algorithm == RS256 && rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature) == nil
+	case *ecdsa.PublicKey:
+		valid = algorithm == ES256 && len(signature) == 64 && ecdsa.Verify(publicKey, digest[:],
+			new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:]))
+	}
+	if !valid {
+		return nil, errors.New("jwt: invalid signature")
+	}
+	encodedClaims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	claims := map[string]interface{}{}
+	return claims, json.Unmarshal(encodedClaims, &claims)
+}
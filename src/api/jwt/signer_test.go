@@ -0,0 +1,99 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+	"github.com/JuanAller/request-builder/src/api/mock"
+)
+
+func TestSigner_SignsWithEveryAlgorithm(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	cases := []struct {
+		algorithm  string
+		signingKey interface{}
+		verifyKey  interface{}
+	}{
+		{HS256, []byte("a secret"), []byte("a secret")},
+		{RS256, rsaKey, &rsaKey.PublicKey},
+		{ES256, ecKey, &ecKey.PublicKey},
+	}
+
+	for _, c := range cases {
+		t.Run(c.algorithm, func(t *testing.T) {
+			signer, err := NewSigner(Config{
+				Algorithm: c.algorithm,
+				Key:       c.signingKey,
+				Issuer:    "my-service",
+				Audience:  "partner",
+				Claims:    map[string]interface{}{"scope": "read"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var authorization string
+			responseMap := make(map[string]string)
+			response := builder.Get(&mock.HttpClientMock{
+				MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+					authorization = request.Header.Get("Authorization")
+					return mock.NewJsonResponse(http.StatusOK, map[string]string{})
+				},
+			}, "http://test/jwt").
+				WithAuthorizer(signer).
+				Execute(&responseMap)
+			if response.Error != nil {
+				t.Fatal(response.Error)
+			}
+
+			claims, err := Verify(strings.TrimPrefix(authorization, "Bearer "), c.algorithm, c.verifyKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims["iss"] != "my-service" || claims["aud"] != "partner" || claims["scope"] != "read" {
+				t.Errorf("Unexpected claims : %v ", claims)
+			}
+			if claims["jti"] == nil || claims["exp"] == nil {
+				t.Errorf("Expected jti and exp claims : %v ", claims)
+			}
+		})
+	}
+}
+
+func TestSigner_CachesUntilNearExpiry(t *testing.T) {
+	signer, _ := NewSigner(Config{Algorithm: HS256, Key: []byte("a secret"), TTL: time.Minute})
+	now := time.Now()
+	signer.now = func() time.Time { return now }
+
+	first, _ := signer.Token()
+	now = now.Add(20 * time.Second)
+	if second, _ := signer.Token(); second != first {
+		t.Errorf("Expected cached token")
+	}
+	now = now.Add(20 * time.Second)
+	if third, _ := signer.Token(); third == first {
+		t.Errorf("Expected a new token near expiry")
+	}
+}
+
+func TestNewSigner_InvalidKey(t *testing.T) {
+	if _, err := NewSigner(Config{Algorithm: RS256, Key: []byte("a secret")}); err == nil {
+		t.Errorf("Expected error for mismatched key")
+	}
+	if _, err := NewSigner(Config{Algorithm: RS256, Key: (*rsa.PrivateKey)(nil)}); err == nil {
+		t.Errorf("Expected error for nil RSA key")
+	}
+	if _, err := NewSigner(Config{Algorithm: ES256, Key: (*ecdsa.PrivateKey)(nil)}); err == nil {
+		t.Errorf("Expected error for nil ECDSA key")
+	}
+	if _, err := NewSigner(Config{Algorithm: "none"}); err == nil {
+		t.Errorf("Expected error for unsupported algorithm")
+	}
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+	"github.com/JuanAller/request-builder/src/api/caller"
+)
+
+type Labels struct {
+	Method string
+	Host   string
+	// Path is the path of the URL template given to the builder, so path
+	// params do not create a series per value.
+	Path        string
+	StatusClass string
+}
+
+type Recorder interface {
+	AddInFlight(labels Labels, delta float64)
+	ObserveRequest(labels Labels, duration time.Duration)
+	// ObserveResponseSize is only called when the size of the response is
+	// known, so not for chunked responses.
+	ObserveResponseSize(labels Labels, responseSize int64)
+	IncRetry(labels Labels)
+}
+
+// TimingRecorder is implemented by recorders that also want the connection
+// timing of requests built WithTiming.
+type TimingRecorder interface {
+	ObserveTiming(labels Labels, timing builder.Timing)
+}
+
+// Middleware records every request sent by a builder, except hedged requests
+// that lost.
+func Middleware(recorder Recorder) builder.Middleware {
+	return func(next builder.HttpClient) builder.HttpClient {
+		return builder.HttpClientFunc(func(request *http.Request) (*http.Response, error) {
+			labels := RequestLabels(request)
+			recorder.AddInFlight(labels, 1)
+			defer recorder.AddInFlight(labels, -1)
+
+			start := time.Now()
+			response, err := next.Do(request)
+			if builder.HedgeLost(request) {
+				return response, err
+			}
+			if err != nil {
+				labels.StatusClass = StatusClass(0)
+				recorder.ObserveRequest(labels, time.Since(start))
+				return response, err
+			}
+			labels.StatusClass = StatusClass(response.StatusCode)
+			recorder.ObserveRequest(labels, time.Since(start))
+			if response.ContentLength >= 0 {
+				recorder.ObserveResponseSize(labels, response.ContentLength)
+			}
+			if timingRecorder, ok := recorder.(TimingRecorder); ok {
+				if timing, ok := builder.RequestTiming(request); ok {
+					timingRecorder.ObserveTiming(labels, timing)
+				}
+			}
+			return response, nil
+		})
+	}
+}
+
+// RetryHook counts the retries of a restCaller. The status class is taken from
+// the response that caused each retry.
+func RetryHook(recorder Recorder, labels Labels) caller.RetryHook {
+	return func(retryNumber int, resp *builder.Response, err error) {
+		retryLabels := labels
+		retryLabels.StatusClass = StatusClass(0)
+		if resp != nil {
+			retryLabels.StatusClass = StatusClass(resp.StatusCode)
+		}
+		recorder.IncRetry(retryLabels)
+	}
+}
+
+func RequestLabels(request *http.Request) Labels {
+	path := request.URL.Path
+	if template, err := url.Parse(builder.URLTemplate(request)); err == nil && template.Path != "" {
+		path = template.Path
+	}
+	return Labels{
+		Method: request.Method,
+		Host:   request.URL.Host,
+		Path:   path,
+	}
+}
+
+func StatusClass(statusCode int) string {
+	if statusCode < 100 || statusCode > 599 {
+		return "error"
+	}
+	return strconv.Itoa(statusCode/100) + "xx"
+}
@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+	"github.com/JuanAller/request-builder/src/api/mock"
+)
+
+type recorderMock struct {
+	inFlight     float64
+	maxInFlight  float64
+	observations []Labels
+	sizes        []int64
+}
+
+func (r *recorderMock) AddInFlight(labels Labels, delta float64) {
+	r.inFlight += delta
+	if r.inFlight > r.maxInFlight {
+		r.maxInFlight = r.inFlight
+	}
+}
+
+func (r *recorderMock) ObserveRequest(labels Labels, duration time.Duration) {
+	r.observations = append(r.observations, labels)
+}
+
+func (r *recorderMock) ObserveResponseSize(labels Labels, responseSize int64) {
+	r.sizes = append(r.sizes, responseSize)
+}
+
+func (r *recorderMock) IncRetry(labels Labels) {}
+
+type timingRecorderMock struct {
+	recorderMock
+	timings []builder.Timing
+}
+
+func (r *timingRecorderMock) ObserveTiming(labels Labels, timing builder.Timing) {
+	r.timings = append(r.timings, timing)
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := &recorderMock{}
+	fail := false
+	client := &mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			if fail {
+				return nil, errors.New("an error")
+			}
+			response, err := mock.NewJsonResponse(http.StatusNotFound, map[string]string{})
+			response.ContentLength = 2
+			return response, err
+		},
+	}
+	responseMap := make(map[string]string)
+	builder.Get(client, "http://test/items/42?verbose=true").
+		Use(Middleware(recorder)).
+		Execute(&responseMap)
+	fail = true
+	builder.Post(client, "http://test/items").
+		Use(Middleware(recorder)).
+		Execute(&responseMap)
+
+	expected := []Labels{
+		{Method: "GET", Host: "test", Path: "/items/{id}", StatusClass: "4xx"},
+		{Method: "POST", Host: "test", Path: "/items", StatusClass: "error"},
+	}
+	if len(recorder.observations) != len(expected) {
+		t.Fatalf("Expected %v, but got %v", expected, recorder.observations)
+	}
+	for i := range expected {
+		if recorder.observations[i] != expected[i] {
+			t.Errorf("Expected %v, but got %v", expected[i], recorder.observations[i])
+		}
+	}
+	if len(recorder.sizes) != 1 || recorder.sizes[0] != 2 {
+		t.Errorf("Expected a single response size 2, but got %v", recorder.sizes)
+	}
+	if recorder.inFlight != 0 || recorder.maxInFlight != 1 {
+		t.Errorf("Unexpected in flight %v, max %v", recorder.inFlight, recorder.maxInFlight)
+	}
+}
+
+func TestMiddleware_UnknownResponseSize(t *testing.T) {
+	recorder := &recorderMock{}
+	client := &mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			response, err := mock.NewJsonResponse(http.StatusOK, map[string]string{})
+			response.ContentLength = -1
+			return response, err
+		},
+	}
+	builder.Get(client, "http://test/items").Use(Middleware(recorder)).Execute(nil)
+
+	if len(recorder.observations) != 1 || len(recorder.sizes) != 0 {
+		t.Errorf("Expected no size for a chunked response, but got %v", recorder.sizes)
+	}
+}
+
+func TestMiddlewareWithTiming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	recorder := &timingRecorderMock{}
+
+	responseMap := make(map[string]string)
+	builder.Get(http.DefaultClient, server.URL).Use(Middleware(recorder)).Execute(&responseMap)
+	builder.Get(http.DefaultClient, server.URL).WithTiming().Use(Middleware(recorder)).Execute(&responseMap)
+
+	if len(recorder.timings) != 1 || recorder.timings[0].Total <= 0 {
+		t.Errorf("Expected one complete timing, but got %v", recorder.timings)
+	}
+}
@@ -0,0 +1,46 @@
+package prometheus
+
+import (
+	"github.com/JuanAller/request-builder/src/api/bulkhead"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+type bulkheadCollector struct {
+	registry      *bulkhead.Registry
+	inFlight      *prom.Desc
+	waiting       *prom.Desc
+	maxConcurrent *prom.Desc
+	maxQueue      *prom.Desc
+	rejected      *prom.Desc
+}
+
+// NewBulkheadCollector exposes the saturation of the bulkheads of registry,
+// read on every scrape.
+func NewBulkheadCollector(registry *bulkhead.Registry, namespace string) prom.Collector {
+	return &bulkheadCollector{
+		registry:      registry,
+		inFlight:      prom.NewDesc(prom.BuildFQName(namespace, "", "bulkhead_in_flight"), "Requests holding a slot of the bulkhead.", []string{"bulkhead"}, nil),
+		waiting:       prom.NewDesc(prom.BuildFQName(namespace, "", "bulkhead_waiting"), "Requests queued for a slot of the bulkhead.", []string{"bulkhead"}, nil),
+		maxConcurrent: prom.NewDesc(prom.BuildFQName(namespace, "", "bulkhead_max_concurrent"), "Slots of the bulkhead.", []string{"bulkhead"}, nil),
+		maxQueue:      prom.NewDesc(prom.BuildFQName(namespace, "", "bulkhead_max_queue"), "Size of the queue of the bulkhead.", []string{"bulkhead"}, nil),
+		rejected:      prom.NewDesc(prom.BuildFQName(namespace, "", "bulkhead_rejected_total"), "Requests rejected by the bulkhead.", []string{"bulkhead", "reason"}, nil),
+	}
+}
+
+func (c *bulkheadCollector) Describe(descs chan<- *prom.Desc) {
+	for _, desc := range []*prom.Desc{c.inFlight, c.waiting, c.maxConcurrent, c.maxQueue, c.rejected} {
+		descs <- desc
+	}
+}
+
+func (c *bulkheadCollector) Collect(metrics chan<- prom.Metric) {
+	for name, stats := range c.registry.Stats() {
+		metrics <- prom.MustNewConstMetric(c.inFlight, prom.GaugeValue, float64(stats.InFlight), name)
+		metrics <- prom.MustNewConstMetric(c.waiting, prom.GaugeValue, float64(stats.Waiting), name)
+		metrics <- prom.MustNewConstMetric(c.maxConcurrent, prom.GaugeValue, float64(stats.MaxConcurrent), name)
+		metrics <- prom.MustNewConstMetric(c.maxQueue, prom.GaugeValue, float64(stats.MaxQueue), name)
+		for _, reason := range []bulkhead.Reason{bulkhead.QueueFull, bulkhead.QueueTimeout} {
+			metrics <- prom.MustNewConstMetric(c.rejected, prom.CounterValue, float64(stats.Rejected[reason]), name, string(reason))
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package prometheus
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+	"github.com/JuanAller/request-builder/src/api/metrics"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	requestLabels  = []string{"method", "host", "path", "status_class"}
+	inFlightLabels = []string{"method", "host", "path"}
+)
+
+type recorder struct {
+	requests     *prom.CounterVec
+	duration     *prom.HistogramVec
+	responseSize *prom.HistogramVec
+	inFlight     *prom.GaugeVec
+	retries      *prom.CounterVec
+	phases       *prom.HistogramVec
+	connections  *prom.CounterVec
+}
+
+// NewRecorder creates the client metrics under namespace and registers them in registerer.
+func NewRecorder(registerer prom.Registerer, namespace string) (*recorder, error) {
+	r := &recorder{
+		requests: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_client_requests_total",
+			Help:      "Outbound HTTP requests.",
+		}, requestLabels),
+		duration: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_client_request_duration_seconds",
+			Help:      "Latency of outbound HTTP requests.",
+			Buckets:   prom.DefBuckets,
+		}, requestLabels),
+		responseSize: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_client_response_size_bytes",
+			Help:      "Size of outbound HTTP responses, when known.",
+			Buckets:   prom.ExponentialBuckets(128, 4, 8),
+		}, requestLabels),
+		inFlight: prom.NewGaugeVec(prom.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_client_requests_in_flight",
+			Help:      "Outbound HTTP requests waiting for a response.",
+		}, inFlightLabels),
+		retries: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_client_retries_total",
+			Help:      "Retries made by rest callers.",
+		}, requestLabels),
+		phases: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_client_phase_duration_seconds",
+			Help:      "Connection phases of outbound HTTP requests built with timing.",
+			Buckets:   prom.DefBuckets,
+		}, []string{"host", "phase"}),
+		connections: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_client_connections_total",
+			Help:      "Connections used by outbound HTTP requests built with timing.",
+		}, []string{"host", "reused"}),
+	}
+	for _, collector := range []prom.Collector{r.requests, r.duration, r.responseSize, r.inFlight, r.retries, r.phases, r.connections} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *recorder) AddInFlight(labels metrics.Labels, delta float64) {
+	r.inFlight.WithLabelValues(labels.Method, labels.Host, labels.Path).Add(delta)
+}
+
+func (r *recorder) ObserveRequest(labels metrics.Labels, duration time.Duration) {
+	values := []string{labels.Method, labels.Host, labels.Path, labels.StatusClass}
+	r.requests.WithLabelValues(values...).Inc()
+	r.duration.WithLabelValues(values...).Observe(duration.Seconds())
+}
+
+func (r *recorder) ObserveResponseSize(labels metrics.Labels, responseSize int64) {
+	r.responseSize.WithLabelValues(labels.Method, labels.Host, labels.Path, labels.StatusClass).Observe(float64(responseSize))
+}
+
+func (r *recorder) IncRetry(labels metrics.Labels) {
+	r.retries.WithLabelValues(labels.Method, labels.Host, labels.Path, labels.StatusClass).Inc()
+}
+
+func (r *recorder) ObserveTiming(labels metrics.Labels, timing builder.Timing) {
+	phases := map[string]time.Duration{
+		"dns":     timing.DNS,
+		"connect": timing.Connect,
+		"tls":     timing.TLSHandshake,
+		"ttfb":    timing.TimeToFirstByte,
+	}
+	for phase, duration := range phases {
+		if duration > 0 {
+			r.phases.WithLabelValues(labels.Host, phase).Observe(duration.Seconds())
+		}
+	}
+	r.connections.WithLabelValues(labels.Host, strconv.FormatBool(timing.ConnectionReused)).Inc()
+}
@@ -0,0 +1,77 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+	"github.com/JuanAller/request-builder/src/api/bulkhead"
+	"github.com/JuanAller/request-builder/src/api/metrics"
+	"github.com/JuanAller/request-builder/src/api/mock"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecorder(t *testing.T) {
+	registry := prom.NewRegistry()
+	recorder, err := NewRecorder(registry, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			return mock.NewJsonResponse(http.StatusOK, map[string]string{"name": "aName"})
+		},
+	}
+	for _, id := range []string{"1", "2"} {
+		responseMap := make(map[string]string)
+		builder.Get(client, "http://test/users/"+id).
+			Use(metrics.Middleware(recorder)).
+			Execute(&responseMap)
+	}
+	metrics.RetryHook(recorder, metrics.Labels{Method: "GET", Host: "test", Path: "/users/{id}"})(1, &builder.Response{StatusCode: 503}, nil)
+
+	expected := `
+# HELP test_http_client_requests_total Outbound HTTP requests.
+# TYPE test_http_client_requests_total counter
+test_http_client_requests_total{host="test",method="GET",path="/users/{id}",status_class="2xx"} 2
+# HELP test_http_client_retries_total Retries made by rest callers.
+# TYPE test_http_client_retries_total counter
+test_http_client_retries_total{host="test",method="GET",path="/users/{id}",status_class="5xx"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"test_http_client_requests_total", "test_http_client_retries_total"); err != nil {
+		t.Error(err)
+	}
+	if count := testutil.CollectAndCount(recorder.duration); count != 1 {
+		t.Errorf("Expected 1 latency series, but got %v", count)
+	}
+	if value := testutil.ToFloat64(recorder.inFlight.WithLabelValues("GET", "test", "/users/{id}")); value != 0 {
+		t.Errorf("Expected no requests in flight, but got %v", value)
+	}
+}
+
+func TestBulkheadCollector(t *testing.T) {
+	bulkheads := bulkhead.NewRegistry(bulkhead.Config{MaxConcurrent: 1})
+	release, _ := bulkheads.Get("users").Enter(context.Background())
+	defer release()
+	bulkheads.Get("users").Enter(context.Background())
+
+	registry := prom.NewRegistry()
+	registry.MustRegister(NewBulkheadCollector(bulkheads, "test"))
+	expected := `
+# HELP test_bulkhead_in_flight Requests holding a slot of the bulkhead.
+# TYPE test_bulkhead_in_flight gauge
+test_bulkhead_in_flight{bulkhead="users"} 1
+# HELP test_bulkhead_rejected_total Requests rejected by the bulkhead.
+# TYPE test_bulkhead_rejected_total counter
+test_bulkhead_rejected_total{bulkhead="users",reason="queue_full"} 1
+test_bulkhead_rejected_total{bulkhead="users",reason="queue_timeout"} 0
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"test_bulkhead_in_flight", "test_bulkhead_rejected_total"); err != nil {
+		t.Error(err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"net/http"
 	"github.com/JuanAller/request-builder/src/api/builder"
+	"github.com/JuanAller/request-builder/src/api/signer"
 )
 
 func TestHttpClientMock_DoWithJsonBody(t *testing.T) {
@@ -52,3 +53,38 @@ func TestHttpClientMock_DoWithXMLBody(t *testing.T) {
 		t.Errorf("Expected 200 in body response")
 	}
 }
+
+func TestCheckSignature(t *testing.T) {
+	hmacSigner := signer.NewHMAC(signer.HMACConfig{
+		KeyID:   "key-1",
+		Secret:  []byte("a secret"),
+		Headers: []string{"Host", "Content-Type"},
+	})
+	otherSigner := signer.NewHMAC(signer.HMACConfig{
+		KeyID:   "key-1",
+		Secret:  []byte("other secret"),
+		Headers: []string{"Host", "Content-Type"},
+	})
+	mockClient := &HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			if err := CheckSignature(request, hmacSigner); err != nil {
+				return NewJsonResponse(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+			}
+			if err := CheckSignature(request, otherSigner); err == nil {
+				return NewJsonResponse(http.StatusUnauthorized, map[string]string{"error": "expected mismatch"})
+			}
+			return NewJsonResponse(http.StatusOK, map[string]string{"hola": "mundo"})
+		},
+	}
+
+	responseMap := make(map[string]string)
+	response := builder.Post(mockClient, "http://mock.test/hooks?b=2&a=1").
+		WithJSONContentType().
+		WithBody(map[string]string{"event": "created"}).
+		WithSigner(hmacSigner).
+		Execute(&responseMap)
+
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200, but got %v", response.StatusCode)
+	}
+}
@@ -0,0 +1,48 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock is a fake clock whose Sleep advances the time instead of blocking.
+type Clock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+func (clock *Clock) Now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return clock.now
+}
+
+func (clock *Clock) Sleep(ctx context.Context, duration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	clock.now = clock.now.Add(duration)
+	clock.sleeps = append(clock.sleeps, duration)
+	return nil
+}
+
+func (clock *Clock) Advance(duration time.Duration) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	clock.now = clock.now.Add(duration)
+}
+
+// Sleeps returns the durations passed to Sleep, in order.
+func (clock *Clock) Sleeps() []time.Duration {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return append([]time.Duration(nil), clock.sleeps...)
+}
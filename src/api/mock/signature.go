@@ -0,0 +1,42 @@
+package mock
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+)
+
+type RequestVerifier interface {
+	Verify(request *http.Request, body []byte) error
+}
+
+// CheckSignature verifies the signature of a request received by MakeResponseFunction.
+func CheckSignature(request *http.Request, verifier RequestVerifier) error {
+	body, err := RequestBody(request)
+	if err != nil {
+		return err
+	}
+	return verifier.Verify(request, body)
+}
+
+// RequestBody reads the body of request leaving it readable for later checks.
+func RequestBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil {
+		return nil, nil
+	}
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return ioutil.ReadAll(body)
+	}
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+	request.Body.Close()
+	request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
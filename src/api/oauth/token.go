@@ -0,0 +1,31 @@
+package oauth
+
+import (
+	"strings"
+	"time"
+)
+
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresIn   int64     `json:"expires_in"`
+	Expiry      time.Time `json:"-"`
+}
+
+func (token *Token) Type() string {
+	if token.TokenType == "" || strings.EqualFold(token.TokenType, "bearer") {
+		return "Bearer"
+	}
+	return token.TokenType
+}
+
+func (token *Token) authorizationHeader() string {
+	return token.Type() + " " + token.AccessToken
+}
+
+func (token *Token) valid(now time.Time, expiryDelta time.Duration) bool {
+	if token == nil || token.AccessToken == "" {
+		return false
+	}
+	return token.Expiry.IsZero() || now.Add(expiryDelta).Before(token.Expiry)
+}
@@ -0,0 +1,140 @@
+package oauth
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+	"golang.org/x/sync/singleflight"
+)
+
+const defaultExpiryDelta = 10 * time.Second
+
+type Config struct {
+	TokenURL       string
+	ClientID       string
+	ClientSecret   string
+	Scopes         []string
+	EndpointParams map[string]string
+	// CredentialsInBody sends client_id and client_secret as form fields
+	// instead of using HTTP Basic authentication.
+	CredentialsInBody bool
+	// ExpiryDelta is how long before its expiry a cached token is refreshed.
+	ExpiryDelta time.Duration
+}
+
+type RetrieveError struct {
+	StatusCode int
+}
+
+func (e *RetrieveError) Error() string {
+	return fmt.Sprintf("oauth: cannot fetch token, status code %d", e.StatusCode)
+}
+
+type tokenSource struct {
+	client builder.HttpClient
+	config Config
+	mutex  sync.RWMutex
+	token  *Token
+	group  singleflight.Group
+	now    func() time.Time
+}
+
+func NewTokenSource(client builder.HttpClient, config Config) *tokenSource {
+	if config.ExpiryDelta == 0 {
+		config.ExpiryDelta = defaultExpiryDelta
+	}
+	return &tokenSource{
+		client: client,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+func (ts *tokenSource) Token() (*Token, error) {
+	ts.mutex.RLock()
+	token := ts.token
+	ts.mutex.RUnlock()
+	if token.valid(ts.now(), ts.config.ExpiryDelta) {
+		return token, nil
+	}
+	result, err, _ := ts.group.Do("token", func() (interface{}, error) {
+		return ts.fetch()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*Token), nil
+}
+
+// Invalidate drops the cached token so the next call to Token fetches a new one.
+func (ts *tokenSource) Invalidate() {
+	ts.mutex.Lock()
+	ts.token = nil
+	ts.mutex.Unlock()
+}
+
+func (ts *tokenSource) Authorize(request *http.Request) error {
+	token, err := ts.Token()
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", token.authorizationHeader())
+	return nil
+}
+
+// Challenge drops the token rejected by the server, unless it was already
+// replaced by a concurrent refresh, and asks for the request to be retried.
+func (ts *tokenSource) Challenge(request *http.Request, response *http.Response) (bool, error) {
+	ts.mutex.Lock()
+	if ts.token != nil && ts.token.authorizationHeader() == request.Header.Get("Authorization") {
+		ts.token = nil
+	}
+	ts.mutex.Unlock()
+	return true, nil
+}
+
+func (ts *tokenSource) fetch() (*Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if len(ts.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(ts.config.Scopes, " "))
+	}
+	for key, value := range ts.config.EndpointParams {
+		form.Set(key, value)
+	}
+	tokenRequest := builder.Post(ts.client, ts.config.TokenURL).
+		WithFormContentType().
+		Accept(builder.APPLICATIONJSON)
+	if ts.config.CredentialsInBody {
+		form.Set("client_id", ts.config.ClientID)
+		form.Set("client_secret", ts.config.ClientSecret)
+	} else {
+		tokenRequest.WithBasicAuthorization(url.QueryEscape(ts.config.ClientID), url.QueryEscape(ts.config.ClientSecret))
+	}
+
+	requestedAt := ts.now()
+	token := &Token{}
+	response := tokenRequest.WithBody(form).Execute(token)
+	if response.Error != nil {
+		return nil, response.Error
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, &RetrieveError{StatusCode: response.StatusCode}
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("oauth: server response missing access_token")
+	}
+	if token.ExpiresIn > 0 {
+		token.Expiry = requestedAt.Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+
+	ts.mutex.Lock()
+	ts.token = token
+	ts.mutex.Unlock()
+	return token, nil
+}
@@ -0,0 +1,185 @@
+package oauth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+)
+
+type tokenServer struct {
+	*httptest.Server
+	calls     int32
+	expiresIn int64
+	delay     time.Duration
+}
+
+func newTokenServer(t *testing.T, expiresIn int64) *tokenServer {
+	ts := &tokenServer{expiresIn: expiresIn}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		if r.PostForm.Get("grant_type") != "client_credentials" {
+			t.Errorf("Expected client_credentials grant, but got : %v ", r.PostForm.Get("grant_type"))
+		}
+		if r.PostForm.Get("scope") != "read write" {
+			t.Errorf("Expected scope read write, but got : %v ", r.PostForm.Get("scope"))
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "id" || pass != "secret" {
+			t.Errorf("Expected client credentials in basic auth")
+		}
+		time.Sleep(ts.delay)
+		call := atomic.AddInt32(&ts.calls, 1)
+		w.Header().Set("Content-Type", builder.APPLICATIONJSON)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fmt.Sprintf("token-%d", call),
+			"token_type":   "bearer",
+			"expires_in":   ts.expiresIn,
+		})
+	}))
+	return ts
+}
+
+func newSource(server *tokenServer) *tokenSource {
+	return NewTokenSource(http.DefaultClient, Config{
+		TokenURL:     server.URL,
+		ClientID:     "id",
+		ClientSecret: "secret",
+		Scopes:       []string{"read", "write"},
+	})
+}
+
+func TestTokenSource_CachesToken(t *testing.T) {
+	server := newTokenServer(t, 3600)
+	defer server.Close()
+	source := newSource(server)
+
+	for i := 0; i < 3; i++ {
+		token, err := source.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.AccessToken != "token-1" {
+			t.Errorf("Expected token-1, but got : %v ", token.AccessToken)
+		}
+	}
+	if server.calls != 1 {
+		t.Errorf("Expected 1 token request, but got : %v ", server.calls)
+	}
+}
+
+func TestTokenSource_RefreshesBeforeExpiry(t *testing.T) {
+	server := newTokenServer(t, 60)
+	defer server.Close()
+	source := newSource(server)
+	now := time.Now()
+	source.now = func() time.Time { return now }
+
+	if token, _ := source.Token(); token.AccessToken != "token-1" {
+		t.Errorf("Expected token-1, but got : %v ", token.AccessToken)
+	}
+	now = now.Add(45 * time.Second)
+	if token, _ := source.Token(); token.AccessToken != "token-1" {
+		t.Errorf("Expected cached token-1, but got : %v ", token.AccessToken)
+	}
+	now = now.Add(10 * time.Second)
+	if token, _ := source.Token(); token.AccessToken != "token-2" {
+		t.Errorf("Expected token-2 inside the expiry delta, but got : %v ", token.AccessToken)
+	}
+}
+
+func TestTokenSource_DeduplicatesConcurrentRefreshes(t *testing.T) {
+	server := newTokenServer(t, 3600)
+	server.delay = 50 * time.Millisecond
+	defer server.Close()
+	source := newSource(server)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := source.Token(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if server.calls != 1 {
+		t.Errorf("Expected 1 token request, but got : %v ", server.calls)
+	}
+}
+
+func TestTokenSource_RetriesOnceOnUnauthorized(t *testing.T) {
+	server := newTokenServer(t, 3600)
+	defer server.Close()
+	source := newSource(server)
+	var resourceCalls int32
+	resource := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&resourceCalls, 1)
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", builder.APPLICATIONJSON)
+		w.Write([]byte(`{"name":"aName"}`))
+	}))
+	defer resource.Close()
+
+	responseMap := make(map[string]string)
+	response := builder.Get(http.DefaultClient, resource.URL).
+		WithAuthorizer(source).
+		Execute(&responseMap)
+
+	if response.Error != nil || response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, but got : %v %v ", response.StatusCode, response.Error)
+	}
+	if responseMap["name"] != "aName" {
+		t.Errorf("Expected aName")
+	}
+	if resourceCalls != 2 || server.calls != 2 {
+		t.Errorf("Expected 2 resource and 2 token requests, but got : %v %v ", resourceCalls, server.calls)
+	}
+}
+
+func TestTokenSource_DoesNotRetryTwice(t *testing.T) {
+	server := newTokenServer(t, 3600)
+	defer server.Close()
+	var resourceCalls int32
+	resource := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&resourceCalls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer resource.Close()
+
+	response := builder.Get(http.DefaultClient, resource.URL).
+		WithAuthorizer(newSource(server)).
+		Execute(nil)
+
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401, but got : %v ", response.StatusCode)
+	}
+	if resourceCalls != 2 {
+		t.Errorf("Expected 2 resource requests, but got : %v ", resourceCalls)
+	}
+}
+
+func TestTokenSource_EndpointError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+	source := NewTokenSource(http.DefaultClient, Config{TokenURL: server.URL})
+
+	_, err := source.Token()
+	if retrieveErr, ok := err.(*RetrieveError); !ok || retrieveErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected RetrieveError with 400, but got : %v ", err)
+	}
+}
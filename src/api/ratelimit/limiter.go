@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+type Config struct {
+	// Rate is the number of requests allowed per second.
+	Rate float64
+	// Burst is the size of the bucket, 1 by default.
+	Burst int
+	// FailFast returns ErrRateLimited instead of waiting for a token.
+	FailFast bool
+	// Adaptive lowers the rate from the rate limit headers of responses, never above Rate.
+	Adaptive bool
+	Now      func() time.Time
+}
+
+type Limiter struct {
+	config       Config
+	mutex        sync.Mutex
+	rate         float64
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+}
+
+func NewLimiter(config Config) *Limiter {
+	if config.Burst <= 0 {
+		config.Burst = 1
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return &Limiter{
+		config: config,
+		rate:   config.Rate,
+		tokens: float64(config.Burst),
+		last:   config.Now(),
+	}
+}
+
+func (l *Limiter) Rate() float64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.rate
+}
+
+// Wait takes a token, waiting for it unless the limiter fails fast. It fails
+// without waiting when ctx would expire first.
+func (l *Limiter) Wait(ctx context.Context) error {
+	l.mutex.Lock()
+	now := l.config.Now()
+	l.refill(now)
+	var wait time.Duration
+	if now.Before(l.blockedUntil) {
+		wait = l.blockedUntil.Sub(now)
+	}
+	if l.tokens < 1 {
+		if l.rate <= 0 {
+			l.mutex.Unlock()
+			return ErrRateLimited
+		}
+		if refill := time.Duration((1 - l.tokens) / l.rate * float64(time.Second)); refill > wait {
+			wait = refill
+		}
+	}
+	if wait > 0 && l.config.FailFast {
+		l.mutex.Unlock()
+		return ErrRateLimited
+	}
+	if deadline, ok := ctx.Deadline(); ok && wait > 0 && deadline.Sub(now) < wait {
+		l.mutex.Unlock()
+		return ErrRateLimited
+	}
+	l.tokens--
+	l.mutex.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mutex.Lock()
+		l.tokens++
+		l.mutex.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (l *Limiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = math.Min(float64(l.config.Burst), l.tokens+elapsed.Seconds()*l.rate)
+		l.last = now
+	}
+}
+
+// Adapt updates the limiter from X-RateLimit-Remaining and X-RateLimit-Reset,
+// or the RateLimit-Remaining and RateLimit-Reset headers. Reset is read as
+// seconds, or as a Unix time when it is larger than the current one.
+func (l *Limiter) Adapt(header http.Header) {
+	remaining, reset, ok := parseRateLimit(header)
+	if !ok {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	now := l.config.Now()
+	if reset > int64(now.Unix()) {
+		reset -= now.Unix()
+	}
+	l.refill(now)
+	if remaining <= 0 {
+		l.tokens = math.Min(l.tokens, 0)
+		l.blockedUntil = now.Add(time.Duration(reset) * time.Second)
+		return
+	}
+	l.rate = l.config.Rate
+	if reset > 0 {
+		l.rate = math.Min(l.config.Rate, float64(remaining)/float64(reset))
+	}
+	l.tokens = math.Min(l.tokens, float64(remaining))
+}
+
+func parseRateLimit(header http.Header) (remaining int64, reset int64, ok bool) {
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		value := header.Get(prefix + "Remaining")
+		if value == "" {
+			continue
+		}
+		remaining, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		reset, _ := strconv.ParseInt(strings.TrimSpace(header.Get(prefix+"Reset")), 10, 64)
+		return remaining, reset, true
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,131 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+)
+
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestLimiter_FailFast(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	limiter := NewLimiter(Config{Rate: 2, Burst: 2, FailFast: true, Now: clock.Now})
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if err := limiter.Wait(context.Background()); err != ErrRateLimited {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+	clock.Advance(500 * time.Millisecond)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("expected a refilled token, got %v", err)
+	}
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	limiter := NewLimiter(Config{Rate: 20})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("expected to wait for two tokens, waited %v", elapsed)
+	}
+}
+
+func TestLimiter_WaitRespectsContext(t *testing.T) {
+	limiter := NewLimiter(Config{Rate: 1})
+	limiter.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); err != ErrRateLimited {
+		t.Fatalf("expected ErrRateLimited before the deadline, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if err := limiter.Wait(ctx); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if limiter.tokens < -0.01 {
+		t.Fatalf("expected the cancelled token to be returned, tokens %v", limiter.tokens)
+	}
+}
+
+func TestLimiter_Adapt(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	limiter := NewLimiter(Config{Rate: 100, Burst: 10, FailFast: true, Adaptive: true, Now: clock.Now})
+
+	limiter.Adapt(http.Header{"X-Ratelimit-Remaining": {"10"}, "X-Ratelimit-Reset": {"20"}})
+	if rate := limiter.Rate(); rate != 0.5 {
+		t.Fatalf("expected rate 0.5, got %v", rate)
+	}
+
+	limiter.Adapt(http.Header{"Ratelimit-Remaining": {"0"}, "Ratelimit-Reset": {strconv.Itoa(1000 + 5)}})
+	if err := limiter.Wait(context.Background()); err != ErrRateLimited {
+		t.Fatalf("expected ErrRateLimited until reset, got %v", err)
+	}
+	clock.Advance(5 * time.Second)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("expected a token after reset, got %v", err)
+	}
+
+	limiter.Adapt(http.Header{"X-Ratelimit-Remaining": {"1000"}, "X-Ratelimit-Reset": {"1"}})
+	if rate := limiter.Rate(); rate != 100 {
+		t.Fatalf("expected rate capped at 100, got %v", rate)
+	}
+}
+
+func TestPerHostMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	other := httptest.NewServer(server.Config.Handler)
+	defer other.Close()
+
+	middleware := PerHostMiddleware(Config{Rate: 1, FailFast: true})
+	execute := func(url string) *builder.Response {
+		var entity map[string]interface{}
+		return builder.Get(http.DefaultClient, url).Use(middleware).Execute(&entity)
+	}
+
+	if response := execute(server.URL); response.Error != nil {
+		t.Fatal(response.Error)
+	}
+	if response := execute(other.URL); response.Error != nil {
+		t.Fatalf("expected a separate limiter per host, got %v", response.Error)
+	}
+	if response := execute(server.URL); response.Error == nil {
+		t.Fatal("expected the second request to the same host to be limited")
+	}
+}
@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+	"github.com/JuanAller/request-builder/src/api/dependency"
+)
+
+// Middleware limits the requests of a builder, or of every builder when
+// installed with builder.Use.
+func Middleware(limiter *Limiter) builder.Middleware {
+	return PerKeyMiddleware(func(request *http.Request) *Limiter {
+		return limiter
+	})
+}
+
+// PerHostMiddleware keeps a limiter per host, created from config.
+func PerHostMiddleware(config Config) builder.Middleware {
+	limiters := dependency.NewRegistry(func(host string) *Limiter {
+		return NewLimiter(config)
+	})
+	return PerKeyMiddleware(func(request *http.Request) *Limiter {
+		return limiters.Get(dependency.ByHost(request))
+	})
+}
+
+func PerKeyMiddleware(limiterFor func(request *http.Request) *Limiter) builder.Middleware {
+	return func(next builder.HttpClient) builder.HttpClient {
+		return builder.HttpClientFunc(func(request *http.Request) (*http.Response, error) {
+			limiter := limiterFor(request)
+			if err := limiter.Wait(request.Context()); err != nil {
+				return nil, err
+			}
+			response, err := next.Do(request)
+			if err == nil && limiter.config.Adaptive {
+				limiter.Adapt(response.Header)
+			}
+			return response, err
+		})
+	}
+}
+
+// Acquire lets a Limiter guard the attempts of a restCaller.
+func (l *Limiter) Acquire(ctx context.Context) (func(resp *builder.Response, err error), error) {
+	if err := l.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return func(resp *builder.Response, err error) {
+		if resp != nil && l.config.Adaptive {
+			l.Adapt(resp.Header)
+		}
+	}, nil
+}
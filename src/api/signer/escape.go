@@ -0,0 +1,22 @@
+package signer
+
+import "strings"
+
+const upperHex = "0123456789ABCDEF"
+
+// escape percent-encodes everything but the RFC 3986 unreserved characters.
+func escape(s string) string {
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			builder.WriteByte(c)
+			continue
+		}
+		builder.WriteByte('%')
+		builder.WriteByte(upperHex[c>>4])
+		builder.WriteByte(upperHex[c&15])
+	}
+	return builder.String()
+}
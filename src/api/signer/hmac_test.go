@@ -0,0 +1,73 @@
+package signer
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSignedRequest(t *testing.T, s *hmacSigner, body string) *http.Request {
+	request, _ := http.NewRequest(http.MethodPost, "http://api.test/v1/items?b=2&a=1&a=0", bytes.NewBufferString(body))
+	request.Header.Set("Content-Type", "application/json")
+	if err := s.Sign(request, []byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	return request
+}
+
+func TestHMAC_CanonicalRequest(t *testing.T) {
+	s := NewHMAC(HMACConfig{KeyID: "key-1", Secret: []byte("secret"), Headers: []string{"Host", "Content-Type"}})
+	s.now = func() time.Time { return time.Unix(1700000000, 0) }
+	request := newSignedRequest(t, s, `{"a":1}`)
+
+	canonical, err := s.CanonicalRequest(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		"POST",
+		"/v1/items",
+		"a=0&a=1&b=2",
+		"host:api.test",
+		"content-type:application/json",
+		"1700000000",
+		"015abd7f5cc57a2dd94b7590f04ad8084273905ee33ec5cebeae62276a97f862",
+	}, "\n")
+	if canonical != expected {
+		t.Errorf("Expected canonical request :\n%v\nbut got :\n%v", expected, canonical)
+	}
+	if !strings.Contains(request.Header.Get("Signature"), `keyId="key-1",headers="host;content-type"`) {
+		t.Errorf("Unexpected signature header : %v ", request.Header.Get("Signature"))
+	}
+}
+
+func TestHMAC_Verify(t *testing.T) {
+	s := NewHMAC(HMACConfig{KeyID: "key-1", Secret: []byte("secret"), Headers: []string{"Content-Type"}, MaxSkew: time.Minute})
+
+	if err := s.Verify(newSignedRequest(t, s, "body"), []byte("body")); err != nil {
+		t.Errorf("Not expected error : %v ", err)
+	}
+	if err := s.Verify(newSignedRequest(t, s, "body"), []byte("tampered")); err == nil {
+		t.Errorf("Expected digest mismatch")
+	}
+	tampered := newSignedRequest(t, s, "body")
+	tampered.Header.Set("Content-Type", "text/plain")
+	if err := s.Verify(tampered, []byte("body")); err == nil {
+		t.Errorf("Expected signature mismatch")
+	}
+	old := newSignedRequest(t, s, "body")
+	s.now = func() time.Time { return time.Now().Add(time.Hour) }
+	if err := s.Verify(old, []byte("body")); err == nil {
+		t.Errorf("Expected skew error")
+	}
+}
+
+func TestHMAC_MissingSignedHeader(t *testing.T) {
+	s := NewHMAC(HMACConfig{Secret: []byte("secret"), Headers: []string{"X-Tenant"}})
+	request, _ := http.NewRequest(http.MethodGet, "http://api.test/", nil)
+	if err := s.Sign(request, nil); err == nil {
+		t.Errorf("Expected missing header error")
+	}
+}
@@ -0,0 +1,169 @@
+package signer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm   = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat  = "20060102T150405Z"
+	sigV4DateFormat  = "20060102"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	amzDateHeader    = "X-Amz-Date"
+	amzContentHeader = "X-Amz-Content-Sha256"
+	amzTokenHeader   = "X-Amz-Security-Token"
+)
+
+var sigV4IgnoredHeaders = map[string]bool{
+	"authorization":   true,
+	"user-agent":      true,
+	"x-amzn-trace-id": true,
+	"expect":          true,
+}
+
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+type CredentialsProvider interface {
+	Retrieve() (Credentials, error)
+}
+
+type StaticCredentials Credentials
+
+func (c StaticCredentials) Retrieve() (Credentials, error) {
+	return Credentials(c), nil
+}
+
+type SigV4Config struct {
+	Service     string
+	Region      string
+	Credentials CredentialsProvider
+	// UnsignedPayload skips hashing the body, as allowed by S3 over TLS.
+	UnsignedPayload bool
+	// DisableURIPathEscaping must be set for S3, whose paths are escaped only once.
+	DisableURIPathEscaping bool
+}
+
+type sigV4Signer struct {
+	config SigV4Config
+	now    func() time.Time
+}
+
+func NewSigV4(config SigV4Config) *sigV4Signer {
+	return &sigV4Signer{
+		config: config,
+		now:    time.Now,
+	}
+}
+
+func (s *sigV4Signer) Sign(request *http.Request, body []byte) error {
+	credentials, err := s.config.Credentials.Retrieve()
+	if err != nil {
+		return err
+	}
+	now := s.now().UTC()
+	request.Header.Set(amzDateHeader, now.Format(sigV4TimeFormat))
+	if credentials.SessionToken != "" {
+		request.Header.Set(amzTokenHeader, credentials.SessionToken)
+	}
+	payloadHash := unsignedPayload
+	if !s.config.UnsignedPayload {
+		payloadHash = hashHex(body)
+	}
+	if s.config.UnsignedPayload || s.config.Service == "s3" {
+		request.Header.Set(amzContentHeader, payloadHash)
+	}
+
+	canonicalRequest, signedHeaders := s.canonicalRequest(request, payloadHash)
+	scope := strings.Join([]string{now.Format(sigV4DateFormat), s.config.Region, s.config.Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		now.Format(sigV4TimeFormat),
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), now.Format(sigV4DateFormat))
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, s.config.Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", sigV4Algorithm+
+		" Credential="+credentials.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+
+		", Signature="+signature)
+	return nil
+}
+
+func (s *sigV4Signer) canonicalRequest(request *http.Request, payloadHash string) (string, string) {
+	path := request.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if !s.config.DisableURIPathEscaping {
+		path = escapePath(path)
+	}
+
+	headers := map[string][]string{"host": {request.Host}}
+	if request.Host == "" {
+		headers["host"] = []string{request.URL.Host}
+	}
+	for name, values := range request.Header {
+		name = strings.ToLower(name)
+		if !sigV4IgnoredHeaders[name] {
+			headers[name] = values
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		values := make([]string, len(headers[name]))
+		for i, value := range headers[name] {
+			values[i] = strings.Join(strings.Fields(value), " ")
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.Join(values, ",") + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	return strings.Join([]string{
+		request.Method,
+		path,
+		canonicalQuery(request),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n"), signedHeaders
+}
+
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func hashHex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
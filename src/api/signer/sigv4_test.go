@@ -0,0 +1,113 @@
+package signer
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Vectors from the AWS Signature Version 4 test suite and the IAM example of the
+// signing documentation.
+func TestSigV4_TestSuite(t *testing.T) {
+	credentials := StaticCredentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	cases := []struct {
+		name          string
+		service       string
+		method        string
+		url           string
+		headers       map[string]string
+		body          string
+		authorization string
+	}{
+		{
+			name:          "get-vanilla",
+			service:       "service",
+			method:        http.MethodGet,
+			url:           "https://example.amazonaws.com/",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:          "get-vanilla-query-order-key-case",
+			service:       "service",
+			method:        http.MethodGet,
+			url:           "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:          "post-vanilla",
+			service:       "service",
+			method:        http.MethodPost,
+			url:           "https://example.amazonaws.com/",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:          "post-x-www-form-urlencoded",
+			service:       "service",
+			method:        http.MethodPost,
+			url:           "https://example.amazonaws.com/",
+			headers:       map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			body:          "Param1=value1",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+		{
+			name:          "iam-list-users",
+			service:       "iam",
+			method:        http.MethodGet,
+			url:           "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08",
+			headers:       map[string]string{"Content-Type": "application/x-www-form-urlencoded; charset=utf-8"},
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := NewSigV4(SigV4Config{Service: c.service, Region: "us-east-1", Credentials: credentials})
+			s.now = func() time.Time { return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC) }
+			request, _ := http.NewRequest(c.method, c.url, bytes.NewBufferString(c.body))
+			for key, value := range c.headers {
+				request.Header.Set(key, value)
+			}
+			if err := s.Sign(request, []byte(c.body)); err != nil {
+				t.Fatal(err)
+			}
+			if request.Header.Get("X-Amz-Date") != "20150830T123600Z" {
+				t.Errorf("Unexpected X-Amz-Date : %v ", request.Header.Get("X-Amz-Date"))
+			}
+			if request.Header.Get("Authorization") != c.authorization {
+				t.Errorf("Expected : %v\nbut got : %v ", c.authorization, request.Header.Get("Authorization"))
+			}
+		})
+	}
+}
+
+func TestSigV4_UnsignedPayloadAndSessionToken(t *testing.T) {
+	s := NewSigV4(SigV4Config{
+		Service:                "s3",
+		Region:                 "eu-west-1",
+		Credentials:            StaticCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"},
+		UnsignedPayload:        true,
+		DisableURIPathEscaping: true,
+	})
+	request, _ := http.NewRequest(http.MethodPut, "https://bucket.s3.amazonaws.com/my%20key", bytes.NewBufferString("data"))
+	if err := s.Sign(request, []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	if request.Header.Get("X-Amz-Content-Sha256") != "UNSIGNED-PAYLOAD" {
+		t.Errorf("Expected UNSIGNED-PAYLOAD, but got : %v ", request.Header.Get("X-Amz-Content-Sha256"))
+	}
+	if request.Header.Get("X-Amz-Security-Token") != "token" {
+		t.Errorf("Expected security token header")
+	}
+	if !strings.Contains(request.Header.Get("Authorization"), "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token,") {
+		t.Errorf("Unexpected signed headers : %v ", request.Header.Get("Authorization"))
+	}
+	canonical, _ := s.canonicalRequest(request, unsignedPayload)
+	if !strings.HasPrefix(canonical, "PUT\n/my%20key\n") {
+		t.Errorf("Expected path escaped once : %v ", canonical)
+	}
+}
@@ -0,0 +1,142 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+	"github.com/JuanAller/request-builder/src/api/caller"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/JuanAller/request-builder/src/api/tracing"
+
+type Config struct {
+	// TracerProvider defaults to the global provider.
+	TracerProvider trace.TracerProvider
+	// Propagator defaults to W3C trace context.
+	Propagator propagation.TextMapPropagator
+}
+
+func (config Config) tracer() trace.Tracer {
+	provider := config.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return provider.Tracer(instrumentationName)
+}
+
+// Middleware starts a client span per request and injects its context in the
+// request headers. The span ends when the response body is closed.
+func Middleware(config Config) builder.Middleware {
+	tracer := config.tracer()
+	propagator := config.Propagator
+	if propagator == nil {
+		propagator = propagation.TraceContext{}
+	}
+	return func(next builder.HttpClient) builder.HttpClient {
+		return builder.HttpClientFunc(func(request *http.Request) (*http.Response, error) {
+			path := request.URL.Path
+			if template, err := url.Parse(builder.URLTemplate(request)); err == nil && template.Path != "" {
+				path = template.Path
+			}
+			ctx, span := tracer.Start(request.Context(), request.Method+" "+path,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("http.request.method", request.Method),
+					attribute.String("url.full", request.URL.Redacted()),
+					attribute.String("url.template", path),
+					attribute.String("server.address", request.URL.Hostname()),
+				))
+
+			request = request.WithContext(ctx)
+			request.Header = request.Header.Clone()
+			propagator.Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+			response, err := next.Do(request)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				span.End()
+				return response, err
+			}
+			span.SetAttributes(attribute.Int("http.response.status_code", response.StatusCode))
+			if response.StatusCode >= 400 {
+				span.SetStatus(codes.Error, strconv.Itoa(response.StatusCode))
+			}
+			if response.Body == nil {
+				span.End()
+				return response, nil
+			}
+			response.Body = &spanBody{ReadCloser: response.Body, span: span}
+			return response, nil
+		})
+	}
+}
+
+// spanBody ends the span of a response when its body is closed, recording
+// the errors found while reading it.
+type spanBody struct {
+	io.ReadCloser
+	span trace.Span
+	once sync.Once
+}
+
+func (body *spanBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		body.span.RecordError(err)
+		body.span.SetStatus(codes.Error, err.Error())
+	}
+	return n, err
+}
+
+func (body *spanBody) Close() error {
+	err := body.ReadCloser.Close()
+	body.once.Do(func() { body.span.End() })
+	return err
+}
+
+type callObserver struct {
+	tracer trace.Tracer
+	name   string
+}
+
+// CallObserver traces a restCaller call as a parent span named name, with a child span per attempt.
+func CallObserver(config Config, name string) caller.CallObserver {
+	return &callObserver{tracer: config.tracer(), name: name}
+}
+
+func (o *callObserver) StartCall(ctx context.Context) (context.Context, func(err error)) {
+	ctx, span := o.tracer.Start(ctx, o.name)
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+func (o *callObserver) StartAttempt(ctx context.Context, attempt int) (context.Context, func(resp *builder.Response, err error)) {
+	ctx, span := o.tracer.Start(ctx, o.name+" attempt",
+		trace.WithAttributes(attribute.Int("retry.attempt", attempt)))
+	return ctx, func(resp *builder.Response, err error) {
+		if resp != nil && resp.StatusCode != 0 {
+			span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,130 @@
+package tracing
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JuanAller/request-builder/src/api/builder"
+	"github.com/JuanAller/request-builder/src/api/caller"
+	"github.com/JuanAller/request-builder/src/api/mock"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newConfig() (Config, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	return Config{TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))}, exporter
+}
+
+func TestMiddleware(t *testing.T) {
+	config, exporter := newConfig()
+	var traceparent string
+	responseMap := make(map[string]string)
+	response := builder.Get(&mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			traceparent = request.Header.Get("traceparent")
+			return mock.NewJsonResponse(http.StatusInternalServerError, map[string]string{})
+		},
+	}, "http://test/users/42").
+		Use(Middleware(config)).
+		Execute(&responseMap)
+
+	if response.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected 500, but got %v", response.StatusCode)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, but got %v", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "GET /users/{id}" || span.SpanKind != trace.SpanKindClient || span.Status.Code != codes.Error {
+		t.Errorf("Unexpected span %v %v %v", span.Name, span.SpanKind, span.Status)
+	}
+	if !strings.Contains(traceparent, span.SpanContext.TraceID().String()+"-"+span.SpanContext.SpanID().String()) {
+		t.Errorf("Expected traceparent with the client span, but got %v", traceparent)
+	}
+}
+
+type failingBody struct{}
+
+func (failingBody) Read(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func (failingBody) Close() error {
+	return nil
+}
+
+func TestMiddleware_EndsSpanWithBody(t *testing.T) {
+	config, exporter := newConfig()
+	client := Middleware(config)(builder.HttpClientFunc(func(request *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: failingBody{}}, nil
+	}))
+	request, _ := http.NewRequest(http.MethodGet, "http://test/users", nil)
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exporter.GetSpans()) != 0 {
+		t.Errorf("Expected the span to last until the body is closed")
+	}
+	if _, err := response.Body.Read(make([]byte, 1)); err == nil {
+		t.Errorf("Expected a read error")
+	}
+	response.Body.Close()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Status.Code != codes.Error || len(spans[0].Events) != 1 {
+		t.Errorf("Expected an ended span with the body error, but got %v", spans)
+	}
+}
+
+func TestCallObserver(t *testing.T) {
+	config, exporter := newConfig()
+	calls := 0
+	request := builder.Get(&mock.HttpClientMock{
+		MakeResponseFunction: func(request *http.Request) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				return mock.NewJsonResponse(http.StatusServiceUnavailable, map[string]string{})
+			}
+			return mock.NewJsonResponse(http.StatusOK, map[string]string{})
+		},
+	}, "http://test/items").Use(Middleware(config))
+
+	responseMap := make(map[string]string)
+	err := caller.NewRestCaller(request, &responseMap, func(resp *builder.Response) (error, bool) {
+		if resp.StatusCode != http.StatusOK {
+			return errors.New("an error"), true
+		}
+		return nil, false
+	}, 2, func(retryNumber int) time.Duration {
+		return 0
+	}).Observe(CallObserver(config, "get items")).ExecuteCall()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	spans := exporter.GetSpans()
+	byName := map[string][]sdktrace.ReadOnlySpan{}
+	for _, span := range spans.Snapshots() {
+		byName[span.Name()] = append(byName[span.Name()], span)
+	}
+	if len(spans) != 5 || len(byName["get items"]) != 1 || len(byName["get items attempt"]) != 2 || len(byName["GET /items"]) != 2 {
+		t.Fatalf("Unexpected spans %v", byName)
+	}
+	parent := byName["get items"][0].SpanContext().SpanID()
+	for i, attempt := range byName["get items attempt"] {
+		if attempt.Parent().SpanID() != parent {
+			t.Errorf("Expected attempt child of the call span")
+		}
+		if byName["GET /items"][i].Parent().SpanID() != attempt.SpanContext().SpanID() {
+			t.Errorf("Expected client span child of the attempt span")
+		}
+	}
+}